@@ -1,74 +1,365 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
 	"fmt"
 	"go-api-testing/config"
+	"go-api-testing/internal/analysis"
+	"go-api-testing/internal/api"
+	"go-api-testing/internal/cassette"
+	"go-api-testing/internal/cli"
 	"go-api-testing/internal/csv"
+	"go-api-testing/internal/dataset"
 	"go-api-testing/internal/db"
+	"go-api-testing/internal/openapi"
 	"go-api-testing/internal/report"
+	"go-api-testing/internal/templating"
 	"go-api-testing/internal/test"
+	"io"
 	"log"
 	"os"
+	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 )
 
+var (
+	markdownFile   = flag.String("markdown", "", "also write a Markdown summary of the run to this `file` (e.g. $GITHUB_STEP_SUMMARY)")
+	markdownAppend = flag.Bool("markdown-append", false, "add the Markdown summary after the current contents of the file instead of replacing them (required for $GITHUB_STEP_SUMMARY)")
+	reportURL      = flag.String("report-url", "", "`URL` of the HTML report linked from the Markdown summary (the report file by default)")
+	tapFile        = flag.String("tap", "", "stream the results in TAP version 14 to this `file` as the tests finish (- for stdout)")
+	plainOutput    = flag.Bool("plain", false, "print plain console output without colors or progress bar, even on a terminal")
+	showTable      = flag.Bool("table", false, "also print the table of all results once the run is over")
+	tags           = flag.String("tags", "", "run only the tests with one of these comma-separated `tags`, and the tests they depend on")
+	contractFile   = flag.String("openapi", "", "validate every request and response against this OpenAPI 3 `document` (YAML or JSON)")
+	recordFile     = flag.String("record", "", "save every request and response of the run into this cassette `file`")
+	replayFile     = flag.String("replay", "", "answer the requests with the responses recorded in this cassette `file`, without calling the APIs")
+)
+
 func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), cli.Usage(), "\nFlags of the test run:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	// Load configuration
 	config.LoadConfig()
+	if config.AppConfig.MaskFields != nil {
+		api.SetSecretFields(config.AppConfig.MaskFields)
+	}
 
-	// Initialize SQLite
-	db.InitDB("data/test_history.db")
+	// Run a subcommand (e.g. "runs") instead of the test suite if one is given. The history
+	// database (SQLite by default, PostgreSQL if DATABASE_URL says so) is only opened for
+	// the commands that use it, and its schema upgraded except for the db subcommand,
+	// which manages the schema itself
+	if args := flag.Args(); len(args) > 0 && cli.IsCommand(args[0]) {
+		switch cli.DatabaseOf(args[0]) {
+		case cli.OpenDatabase:
+			db.OpenDefault(config.AppConfig.DatabaseURL)
+		case cli.MigratedDatabase:
+			db.InitDB(config.AppConfig.DatabaseURL)
+		}
+		if err := cli.Run(args); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	db.InitDB(config.AppConfig.DatabaseURL)
 
 	// Read test cases from CSV
 	testCases, err := csv.ReadCSV(config.AppConfig.TestCasesFile)
 	if err != nil {
 		log.Fatalf("Error reading CSV: %v", err)
 	}
+	if *tags != "" {
+		testCases = test.SelectByTags(testCases, strings.Split(*tags, ","))
+		if len(testCases) == 0 {
+			log.Fatalf("No test cases tagged %s", *tags)
+		}
+	}
+
+	// Load the cassette to replay, whose seed reproduces the generated values it was recorded with
+	var recorded *cassette.Cassette
+	if *recordFile != "" && *replayFile != "" {
+		log.Fatal("--record and --replay cannot be used together")
+	}
+	if *replayFile != "" {
+		if recorded, err = cassette.Load(*replayFile); err != nil {
+			log.Fatalf("Error loading cassette: %v", err)
+		}
+		if os.Getenv("SEED") == "" && recorded.Seed != 0 {
+			config.AppConfig.Seed = recorded.Seed
+		}
+	}
+
+	// Expand data-driven test cases into one case per data row and render template
+	// expressions. The seed is logged so that generated values can be reproduced.
+	log.Printf("Random seed: %d (set SEED=%d to reproduce this run)", config.AppConfig.Seed, config.AppConfig.Seed)
+	testCases, err = dataset.Expand(testCases, templating.NewGenerator(config.AppConfig.Seed))
+	if err != nil {
+		log.Fatalf("Error expanding data-driven tests: %v", err)
+	}
+
+	// Build the dependency graph, rejecting unknown dependencies and cycles
+	plan, err := test.BuildPlan(testCases)
+	if err != nil {
+		log.Fatalf("Invalid test dependencies: %v", err)
+	}
 
-	// Console table
-	table := tablewriter.NewWriter(os.Stdout)
+	// Load the OpenAPI contract the exchanges are validated against, if any
+	if *contractFile != "" {
+		if plan.Contract, err = openapi.Load(*contractFile); err != nil {
+			log.Fatalf("Error loading OpenAPI contract: %v", err)
+		}
+		if len(plan.Contract.Unresolved) > 0 {
+			log.Printf("OpenAPI contract: references that could not be resolved accept any value: %s", strings.Join(plan.Contract.Unresolved, ", "))
+		}
+	}
+
+	// Record the exchanges into a cassette, or serve them from a local stand-in of the APIs
+	var recorder *cassette.Recorder
+	if *recordFile != "" {
+		recorder = cassette.NewRecorder(config.AppConfig.Seed)
+		api.SetRecorder(recorder.Record)
+	}
+	var replay *cassette.Server
+	if recorded != nil {
+		if replay, err = cassette.Serve(recorded); err != nil {
+			log.Fatalf("Error replaying cassette: %v", err)
+		}
+		defer replay.Close()
+		api.SetTransport(replay.Transport())
+		log.Printf("Replaying %d recorded exchange(s) from %s at %s", len(recorded.Interactions), *replayFile, replay.URL)
+	}
+
+	// Record the run so that its results can be grouped in the history
+	run := newRun()
+	run.ID, err = db.Default.StartRun(run)
+	if err != nil {
+		log.Fatalf("Error starting run: %v", err)
+	}
+
+	// Stream TAP as the tests finish. When it goes to stdout, the console output moves to stderr.
+	var console io.Writer = os.Stdout
+	var tap *report.TAPWriter
+	if *tapFile != "" {
+		tapOutput := os.Stdout
+		if *tapFile == "-" {
+			console = os.Stderr
+		} else {
+			if tapOutput, err = os.Create(*tapFile); err != nil {
+				log.Fatalf("Error creating TAP file: %v", err)
+			}
+			defer tapOutput.Close()
+		}
+		tap = report.NewTAPWriter(tapOutput, len(plan.Tests))
+	}
+
+	// Print each test as it finishes, with a progress bar on a terminal
+	progress := report.NewConsoleReporter(console, len(plan.Tests), *plainOutput)
+	onResult := func(r test.Result) {
+		progress.WriteResult(r)
+		if tap != nil {
+			tap.WriteResult(r)
+		}
+	}
+
+	// Table of all the results, printed at the end with --table
+	table := tablewriter.NewWriter(console)
 	table.SetHeader([]string{"TestId", "TestCase", "Result", "Message"})
 
 	results := [][]string{{"TestId", "TestCase", "Result", "Message"}}
+	var executed []test.Result
+	breaches := 0
 
-	// Execute tests
-	for _, tc := range testCases {
-		if tc.Run == "Y" {
-			success, message := test.RunTest(tc)
-			consoleMsg := "Test failed"
-			if success {
-				consoleMsg = "Test successful"
-			}
-			row := []string{tc.TestId, tc.TestCase, fmt.Sprintf("%t", success), consoleMsg}
-			table.Append(row)
-			results = append(results, []string{tc.TestId, tc.TestCase, fmt.Sprintf("%t", success), message})
+	// Execute tests in dependency order
+	for _, r := range plan.Run(config.AppConfig.Parallelism, onResult) {
+		if r.Status == test.StatusSkipped {
+			continue
+		}
+		tc := r.TestCase
+		if r.Exchange != nil {
+			// Mask credentials and bound body sizes before the exchange is stored or shown
+			exchange := r.Exchange.Sanitized(config.AppConfig.MaxBodyBytes)
+			r.Exchange = &exchange
+		}
+		result := fmt.Sprintf("%t", r.Passed())
+		switch r.Status {
+		case test.StatusPassed:
+			run.Passed++
+		case test.StatusBlocked:
+			result = string(test.StatusBlocked)
+			run.Blocked++
+		default:
+			run.Failed++
+		}
+		row := []string{tc.TestId, tc.TestCase, result, r.Summary()}
+		table.Append(row)
+		results = append(results, []string{tc.TestId, tc.TestCase, result, r.Message})
+		executed = append(executed, r)
+		if len(r.Contract) > 0 {
+			breaches++
+		}
 
-			// Save to DB
-			if err := db.SaveResult(tc.TestId, tc.TestCase, success, message); err != nil {
-				log.Printf("Error saving to DB: %v", err)
+		// Save to DB, with the request and response of failed tests
+		resultID, err := db.Default.SaveResult(run.ID, historyResult(r))
+		if err != nil {
+			log.Printf("Error saving to DB: %v", err)
+		} else if r.Status == test.StatusFailed && r.Exchange != nil {
+			if err := db.Default.SaveExchange(resultID, *r.Exchange); err != nil {
+				log.Printf("Error saving exchange to DB: %v", err)
 			}
 		}
 	}
 
-	// Show table in console
-	table.Render()
+	// Close the run with its totals
+	run.EndTime = time.Now()
+	run.Total = len(executed)
+	if err := db.Default.FinishRun(run); err != nil {
+		log.Printf("Error finishing run: %v", err)
+	}
+
+	// Show the summary in console
+	progress.Finish(run.ID)
+	if *showTable {
+		table.Render()
+	}
+	if breaches > 0 {
+		fmt.Fprintf(console, "Contract violations: %d test(s) do not honour %s\n", breaches, *contractFile)
+	}
+	if tap != nil && tap.Err() != nil {
+		log.Printf("%v", tap.Err())
+	}
+	if recorder != nil {
+		c := recorder.Cassette()
+		if err := c.Save(*recordFile); err != nil {
+			log.Fatalf("Error saving cassette: %v", err)
+		}
+		fmt.Fprintf(console, "Recorded %d exchange(s) into %s\n", len(c.Interactions), *recordFile)
+	}
+	if replay != nil {
+		if misses := replay.Misses(); len(misses) > 0 {
+			fmt.Fprintf(console, "Replay: %d request(s) not recorded in %s: %s\n", len(misses), *replayFile, strings.Join(misses, ", "))
+		}
+	}
 
 	// Save CSV
 	if err := csv.WriteResults(results, config.AppConfig.ResultsFile); err != nil {
 		log.Fatalf("Error writing CSV: %v", err)
 	}
 
-	// Get full history
-	historico, err := db.GetHistory()
+	// Apply the retention policy, compacting the database when rows were deleted
+	retention := db.RetentionPolicy{
+		KeepRuns:              config.AppConfig.HistoryKeepRuns,
+		KeepDays:              config.AppConfig.HistoryKeepDays,
+		FailuresOnlyAfterDays: config.AppConfig.HistoryFailuresOnlyAfterDays,
+	}
+	if stats, err := db.Default.Prune(retention, time.Now()); err != nil {
+		log.Printf("Error pruning history: %v", err)
+	} else if stats.Runs+stats.Results > 0 {
+		log.Printf("Pruned history: %d runs, %d results and %d exchanges deleted", stats.Runs, stats.Results, stats.Exchanges)
+		if err := db.Default.Vacuum(); err != nil {
+			log.Printf("Error compacting database: %v", err)
+		}
+	}
+
+	// Get the history of the runs shown in the report
+	historico, err := db.Default.QueryHistory(db.HistoryFilter{LastRuns: config.AppConfig.ReportHistoryRuns})
 	if err != nil {
 		log.Fatalf("Error getting DB history: %v", err)
 	}
+	runs, err := db.Default.GetRuns(config.AppConfig.ReportHistoryRuns)
+	if err != nil {
+		log.Fatalf("Error getting DB runs: %v", err)
+	}
+
+	flaky, err := analysis.FlakyTests(analysis.DefaultFlakyRuns, analysis.DefaultFlakyThreshold)
+	if err != nil {
+		log.Fatalf("Error analysing flaky tests: %v", err)
+	}
 
 	// Generate HTML report with history
-	if err := report.GenerateUltimateReport(results, historico, config.AppConfig.ReportFile); err != nil {
+	reportData := report.ReportData{
+		GeneratedAt: time.Now(),
+		Run:         run,
+		Results:     executed,
+		Runs:        runs,
+		History:     historico,
+		Flaky:       flaky,
+		Latency:     analysis.AnalyseLatency(historico, analysis.DefaultBaselineRuns, analysis.DefaultLatencyThreshold),
+	}
+	if regressed := reportData.Latency.Regressions(); len(regressed) > 0 {
+		fmt.Fprintf(console, "Latency regressions: %d test(s) slower than their baseline\n", len(regressed))
+	}
+
+	// Compare with the previous run of the same suite and environment, if any; the report
+	// is still generated without the comparison if it fails
+	if previous, ok := analysis.PreviousRun(run, runs); ok {
+		comparison, err := analysis.CompareRuns(previous.ID, run.ID, analysis.DefaultLatencyThreshold)
+		if err != nil {
+			log.Printf("Error comparing with run %d: %v", previous.ID, err)
+		} else {
+			reportData.Comparison = &comparison
+			fmt.Fprintf(console, "Since run %d: %d newly failed, %d newly passed, %d slower\n",
+				previous.ID, len(comparison.NewlyFailed), len(comparison.NewlyPassed), len(comparison.Slower))
+		}
+	}
+
+	reportOptions := report.Options{
+		Assets:    report.AssetMode(config.AppConfig.ReportAssets),
+		Templates: config.AppConfig.ReportTemplates,
+	}
+	if err := report.GenerateUltimateReport(reportData, config.AppConfig.ReportFile, reportOptions); err != nil {
 		log.Fatalf("Error generating HTML report: %v", err)
 	}
+	if *markdownFile != "" {
+		if err := report.GenerateMarkdownReport(reportData, *markdownFile, *reportURL, config.AppConfig.ReportFile, *markdownAppend); err != nil {
+			log.Fatalf("Error generating Markdown report: %v", err)
+		}
+	}
 
-	fmt.Println("Tests executed. CSV and HTML report generated.")
+	fmt.Fprintln(console, "Tests executed. CSV and HTML report generated.")
+}
+
+// newRun describes the current execution: when and where it happens and which suite it runs.
+func newRun() db.Run {
+	run := db.Run{
+		StartTime:   time.Now(),
+		Environment: config.AppConfig.Environment,
+		SuiteFile:   config.AppConfig.TestCasesFile,
+		Seed:        config.AppConfig.Seed,
+	}
+	if content, err := os.ReadFile(run.SuiteFile); err == nil {
+		sum := sha256.Sum256(content)
+		run.SuiteHash = hex.EncodeToString(sum[:])
+	}
+	run.Host, _ = os.Hostname()
+
+	// Prefer the commit provided by CI, fall back to the local checkout
+	run.GitCommit = os.Getenv("GITHUB_SHA")
+	if run.GitCommit == "" {
+		if out, err := exec.Command("git", "rev-parse", "HEAD").Output(); err == nil {
+			run.GitCommit = strings.TrimSpace(string(out))
+		}
+	}
+	return run
+}
+
+// historyResult converts the result of a test into the record saved in the history.
+func historyResult(r test.Result) db.Result {
+	return db.Result{
+		TestID:   r.TestCase.TestId,
+		TestCase: r.TestCase.TestCase,
+		Passed:   r.Passed(),
+		Status:   string(r.Status),
+		Message:  r.Message,
+		Duration: r.Duration,
+		Method:   r.TestCase.Method,
+		Endpoint: r.TestCase.Endpoint,
+	}
 }
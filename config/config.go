@@ -5,6 +5,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,10 +16,34 @@ import (
 // TestCasesFile: Path to the CSV file containing the test cases.
 // ResultsFile: Path to the CSV file where test results will be stored.
 // ReportFile: Path to the HTML file where the test report will be generated.
+// Parallelism: Maximum number of test cases executed at the same time.
+// Seed: Seed for the random values generated by template functions.
+// Environment: Name of the environment under test, recorded with each run.
+// MaxBodyBytes: Maximum size of the bodies stored for failed tests.
+// MaskFields: Query parameters and body fields whose values are masked in stored and displayed requests.
+// HistoryKeepRuns, HistoryKeepDays, HistoryFailuresOnlyAfterDays: Retention policy applied to the history after each run.
+// ReportHistoryRuns: Number of recent runs included in the HTML report.
+// ReportAssets: How the report includes its CSS/JS libraries, "cdn" or "inline".
+// ReportTemplates: Custom html/template files used to render the report.
+// DatabaseURL: Location of the history database, a SQLite file or a PostgreSQL URL.
 type Config struct {
-	TestCasesFile string // Path to the test cases CSV file
-	ResultsFile   string // Path to the results CSV file
-	ReportFile    string // Path to the HTML report file
+	TestCasesFile string   // Path to the test cases CSV file
+	ResultsFile   string   // Path to the results CSV file
+	ReportFile    string   // Path to the HTML report file
+	Parallelism   int      // Maximum number of concurrent tests
+	Seed          int64    // Seed for generated template values
+	Environment   string   // Name of the environment under test
+	MaxBodyBytes  int      // Maximum stored body size, in bytes
+	MaskFields    []string // Secret query parameters and body fields (nil uses the default list)
+
+	HistoryKeepRuns              int      // Number of runs kept in the history (0 keeps all)
+	HistoryKeepDays              int      // Days of history kept (0 keeps all)
+	HistoryFailuresOnlyAfterDays int      // Days after which only failed results are kept (0 keeps all)
+	ReportHistoryRuns            int      // Number of runs shown in the report (0 shows all)
+	ReportAssets                 string   // "cdn" to link the report libraries, "inline" to embed them
+	ReportTemplates              []string // Custom report templates, applied over the default one
+
+	DatabaseURL string // SQLite file path or postgres:// URL of the history database
 }
 
 // AppConfig is a global instance of the application configuration.
@@ -50,13 +77,119 @@ func LoadConfig() {
 		log.Fatal("REPORT_FILE is not set. Please set this variable in the .env file.")
 	}
 
+	// Get the optional number of concurrent tests from the PARALLELISM environment variable
+	parallelism := 1
+	if value := os.Getenv("PARALLELISM"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			log.Fatalf("PARALLELISM must be a positive integer, got %q.", value)
+		}
+		parallelism = n
+	}
+
+	// Get the optional seed from the SEED environment variable; a new one is picked for every run otherwise
+	seed := time.Now().UnixNano()
+	if value := os.Getenv("SEED"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Fatalf("SEED must be an integer, got %q.", value)
+		}
+		seed = n
+	}
+
+	// Get the optional name of the environment under test (e.g. "staging") from the ENVIRONMENT environment variable
+	environment := os.Getenv("ENVIRONMENT")
+
+	// Get the optional maximum size of stored request/response bodies from the MAX_BODY_BYTES environment variable
+	maxBodyBytes := nonNegativeInt("MAX_BODY_BYTES", 65536)
+
+	// Get the optional list of secret query parameters and body fields from the MASK_FIELDS environment
+	// variable (comma-separated names); the default list of common secret names is used otherwise
+	var maskFields []string
+	if value, ok := os.LookupEnv("MASK_FIELDS"); ok {
+		maskFields = []string{}
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				maskFields = append(maskFields, name)
+			}
+		}
+	}
+
+	// Get the optional retention policy of the history database; everything is kept by default
+	historyKeepRuns := nonNegativeInt("HISTORY_KEEP_RUNS", 0)
+	historyKeepDays := nonNegativeInt("HISTORY_KEEP_DAYS", 0)
+	historyFailuresOnlyAfterDays := nonNegativeInt("HISTORY_FAILURES_ONLY_AFTER_DAYS", 0)
+
+	// Get the optional number of recent runs included in the HTML report from the REPORT_HISTORY_RUNS environment variable
+	reportHistoryRuns := nonNegativeInt("REPORT_HISTORY_RUNS", 30)
+
+	// Get the optional way the report includes its libraries from the REPORT_ASSETS environment variable
+	reportAssets := strings.ToLower(os.Getenv("REPORT_ASSETS"))
+	switch reportAssets {
+	case "":
+		reportAssets = "cdn"
+	case "cdn", "inline":
+	default:
+		log.Fatalf("REPORT_ASSETS must be cdn or inline, got %q.", reportAssets)
+	}
+
+	// Get the optional custom report templates from the REPORT_TEMPLATE environment variable (comma-separated paths)
+	var reportTemplates []string
+	for _, path := range strings.Split(os.Getenv("REPORT_TEMPLATE"), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			reportTemplates = append(reportTemplates, path)
+		}
+	}
+
+	// Get the optional location of the history database from the DATABASE_URL environment variable:
+	// a SQLite file path (the default) or a postgres:// URL
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		databaseURL = "data/test_history.db"
+	}
+
 	// Assign file paths to AppConfig struct
 	AppConfig = Config{
 		TestCasesFile: testCasesFile,
 		ResultsFile:   resultsFile,
 		ReportFile:    reportFile,
+		Parallelism:   parallelism,
+		Seed:          seed,
+		Environment:   environment,
+		MaxBodyBytes:  maxBodyBytes,
+		MaskFields:    maskFields,
+
+		HistoryKeepRuns:              historyKeepRuns,
+		HistoryKeepDays:              historyKeepDays,
+		HistoryFailuresOnlyAfterDays: historyFailuresOnlyAfterDays,
+		ReportHistoryRuns:            reportHistoryRuns,
+		ReportAssets:                 reportAssets,
+		ReportTemplates:              reportTemplates,
+
+		DatabaseURL: databaseURL,
 	}
 
 	// Confirm that configuration is loaded correctly by showing file paths
 	log.Printf("Configuration loaded successfully. TestCasesFile: %s, ResultsFile: %s, ReportFile: %s", AppConfig.TestCasesFile, AppConfig.ResultsFile, AppConfig.ReportFile)
 }
+
+// nonNegativeInt reads an optional non-negative integer from an environment variable.
+// The program terminates with an error if the value is not a valid non-negative integer.
+//
+// Parameters:
+//   - name (string): Name of the environment variable.
+//   - def (int): Value used when the variable is not set.
+//
+// Returns:
+//   - int: The value of the variable, or def if it is not set.
+func nonNegativeInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Fatalf("%s must be a non-negative integer, got %q.", name, value)
+	}
+	return n
+}
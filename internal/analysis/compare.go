@@ -0,0 +1,239 @@
+package analysis
+
+import (
+	"go-api-testing/internal/db"
+	"math"
+	"sort"
+)
+
+const (
+	DefaultLatencyThreshold = 0.5 // Relative latency change from which a test is reported by default.
+	minLatencyChangeMs      = 20  // Latency changes smaller than this are ignored as noise.
+)
+
+// ChangeKind classifies how a test changed between two runs.
+type ChangeKind string
+
+const (
+	ChangeNewlyFailed ChangeKind = "newly_failed" // Passed in the base run, did not pass in the target run.
+	ChangeNewlyPassed ChangeKind = "newly_passed" // Did not pass in the base run, passed in the target run.
+	ChangeAdded       ChangeKind = "added"        // Only present in the target run.
+	ChangeRemoved     ChangeKind = "removed"      // Only present in the base run.
+	ChangeSlower      ChangeKind = "slower"       // Passed in both runs, with a latency increase beyond the threshold.
+	ChangeFaster      ChangeKind = "faster"       // Passed in both runs, with a latency decrease beyond the threshold.
+)
+
+// TestChange describes how a single test differs between two runs.
+type TestChange struct {
+	TestID        string     `json:"test_id"`
+	TestCase      string     `json:"test_case"`
+	Kind          ChangeKind `json:"kind"`
+	BaseStatus    string     `json:"base_status,omitempty"`   // Empty if the test is not in the base run.
+	TargetStatus  string     `json:"target_status,omitempty"` // Empty if the test is not in the target run.
+	BaseMs        int64      `json:"base_ms"`
+	TargetMs      int64      `json:"target_ms"`
+	LatencyChange float64    `json:"latency_change"`    // (TargetMs - BaseMs) / BaseMs.
+	Message       string     `json:"message,omitempty"` // Message of the target result, for new failures.
+}
+
+// RunComparison lists the differences between a base run and a later target run.
+type RunComparison struct {
+	Base             db.Run       `json:"base"`
+	Target           db.Run       `json:"target"`
+	LatencyThreshold float64      `json:"latency_threshold"`
+	NewlyFailed      []TestChange `json:"newly_failed"`
+	NewlyPassed      []TestChange `json:"newly_passed"`
+	Added            []TestChange `json:"added"`
+	Removed          []TestChange `json:"removed"`
+	Slower           []TestChange `json:"slower"`
+	Faster           []TestChange `json:"faster"`
+	Unchanged        int          `json:"unchanged"` // Tests present in both runs without a reported change.
+}
+
+// Regressions returns the number of tests that got worse: new failures and slowdowns.
+func (c RunComparison) Regressions() int {
+	return len(c.NewlyFailed) + len(c.Slower)
+}
+
+// HasChanges reports whether any test changed between the runs.
+func (c RunComparison) HasChanges() bool {
+	return len(c.NewlyFailed)+len(c.NewlyPassed)+len(c.Added)+len(c.Removed)+len(c.Slower)+len(c.Faster) > 0
+}
+
+// CompareResults compares the results of two runs test by test.
+// Latency is only compared for tests that passed in both runs, and a change is
+// reported when it reaches the threshold relative to the base latency and is at
+// least 20 ms, so that very fast tests do not produce noise.
+//
+// Parameters:
+//   - base ([]db.HistoryEntry): The results of the reference run.
+//   - target ([]db.HistoryEntry): The results of the run under review.
+//   - latencyThreshold (float64): Relative latency change from which a test is reported (0.5 means 50%).
+//
+// Returns:
+//   - RunComparison: The changes found, each list sorted by TestId (latency changes by magnitude).
+//     The Base and Target runs are left empty.
+func CompareResults(base, target []db.HistoryEntry, latencyThreshold float64) RunComparison {
+	c := RunComparison{
+		LatencyThreshold: latencyThreshold,
+		NewlyFailed:      []TestChange{},
+		NewlyPassed:      []TestChange{},
+		Added:            []TestChange{},
+		Removed:          []TestChange{},
+		Slower:           []TestChange{},
+		Faster:           []TestChange{},
+	}
+	before := latestByTest(base)
+	after := latestByTest(target)
+
+	for id, b := range before {
+		if _, ok := after[id]; !ok {
+			c.Removed = append(c.Removed, change(ChangeRemoved, &b, nil))
+		}
+	}
+	for id, a := range after {
+		b, ok := before[id]
+		if !ok {
+			c.Added = append(c.Added, change(ChangeAdded, nil, &a))
+			continue
+		}
+		baseStatus, targetStatus := entryStatus(b), entryStatus(a)
+		ch := change("", &b, &a)
+		switch {
+		case baseStatus == "passed" && targetStatus != "passed":
+			ch.Kind, ch.Message = ChangeNewlyFailed, a.Message
+			c.NewlyFailed = append(c.NewlyFailed, ch)
+		case baseStatus != "passed" && targetStatus == "passed":
+			ch.Kind = ChangeNewlyPassed
+			c.NewlyPassed = append(c.NewlyPassed, ch)
+		case baseStatus == "passed" && b.DurationMs > 0 &&
+			math.Abs(ch.LatencyChange) >= latencyThreshold &&
+			abs(a.DurationMs-b.DurationMs) >= minLatencyChangeMs:
+			if ch.LatencyChange > 0 {
+				ch.Kind = ChangeSlower
+				c.Slower = append(c.Slower, ch)
+			} else {
+				ch.Kind = ChangeFaster
+				c.Faster = append(c.Faster, ch)
+			}
+		default:
+			c.Unchanged++
+		}
+	}
+
+	for _, list := range [][]TestChange{c.NewlyFailed, c.NewlyPassed, c.Added, c.Removed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].TestID < list[j].TestID })
+	}
+	for _, list := range [][]TestChange{c.Slower, c.Faster} {
+		sort.Slice(list, func(i, j int) bool {
+			if math.Abs(list[i].LatencyChange) != math.Abs(list[j].LatencyChange) {
+				return math.Abs(list[i].LatencyChange) > math.Abs(list[j].LatencyChange)
+			}
+			return list[i].TestID < list[j].TestID
+		})
+	}
+	return c
+}
+
+// CompareRuns loads two runs from the history database and compares their results.
+//
+// Parameters:
+//   - baseID (int64): Id of the reference run.
+//   - targetID (int64): Id of the run under review.
+//   - latencyThreshold (float64): Relative latency change from which a test is reported.
+//
+// Returns:
+//   - RunComparison: The changes between the runs.
+//   - error: An error if a run does not exist or the history cannot be read.
+func CompareRuns(baseID, targetID int64, latencyThreshold float64) (RunComparison, error) {
+	var c RunComparison
+	base, err := db.Default.GetRun(baseID)
+	if err != nil {
+		return c, err
+	}
+	target, err := db.Default.GetRun(targetID)
+	if err != nil {
+		return c, err
+	}
+	baseResults, err := db.GetRunResults(baseID)
+	if err != nil {
+		return c, err
+	}
+	targetResults, err := db.GetRunResults(targetID)
+	if err != nil {
+		return c, err
+	}
+	c = CompareResults(baseResults, targetResults, latencyThreshold)
+	c.Base, c.Target = base, target
+	return c, nil
+}
+
+// PreviousRun finds the run to compare a run with: the latest earlier run of the
+// same suite file against the same environment.
+//
+// Parameters:
+//   - run (db.Run): The run under review.
+//   - runs ([]db.Run): The candidate runs, in any order.
+//
+// Returns:
+//   - db.Run: The previous run.
+//   - bool: false if there is no earlier run of the same suite and environment.
+func PreviousRun(run db.Run, runs []db.Run) (db.Run, bool) {
+	var previous db.Run
+	found := false
+	for _, r := range runs {
+		if r.ID < run.ID && r.SuiteFile == run.SuiteFile && r.Environment == run.Environment &&
+			(!found || r.ID > previous.ID) {
+			previous, found = r, true
+		}
+	}
+	return previous, found
+}
+
+// latestByTest indexes results by TestId, keeping the last one if a test appears twice.
+func latestByTest(entries []db.HistoryEntry) map[string]db.HistoryEntry {
+	byTest := make(map[string]db.HistoryEntry, len(entries))
+	for _, e := range entries {
+		if prev, ok := byTest[e.TestID]; !ok || e.ID > prev.ID {
+			byTest[e.TestID] = e
+		}
+	}
+	return byTest
+}
+
+// change builds a TestChange from the base and target results, either of which may be nil.
+func change(kind ChangeKind, base, target *db.HistoryEntry) TestChange {
+	ch := TestChange{Kind: kind}
+	if base != nil {
+		ch.TestID, ch.TestCase = base.TestID, base.TestCase
+		ch.BaseStatus, ch.BaseMs = entryStatus(*base), base.DurationMs
+	}
+	if target != nil {
+		ch.TestID, ch.TestCase = target.TestID, target.TestCase
+		ch.TargetStatus, ch.TargetMs = entryStatus(*target), target.DurationMs
+	}
+	if base != nil && target != nil && base.DurationMs > 0 {
+		ch.LatencyChange = float64(target.DurationMs-base.DurationMs) / float64(base.DurationMs)
+	}
+	return ch
+}
+
+// entryStatus returns the status of a saved result, deriving it for results saved before statuses existed.
+func entryStatus(e db.HistoryEntry) string {
+	switch {
+	case e.Status != "":
+		return e.Status
+	case e.Result:
+		return "passed"
+	default:
+		return "failed"
+	}
+}
+
+// abs returns the absolute value of n.
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
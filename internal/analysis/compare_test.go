@@ -0,0 +1,143 @@
+package analysis
+
+import (
+	"go-api-testing/internal/db"
+	"reflect"
+	"testing"
+)
+
+func TestCompareResults(t *testing.T) {
+	var base, target []db.HistoryEntry
+	add := func(list *[]db.HistoryEntry, testID, status string, ms int64) {
+		*list = append(*list, db.HistoryEntry{ID: int64(len(base) + len(target) + 1), TestID: testID, TestCase: testID, Status: status, DurationMs: ms, Message: status + " " + testID})
+	}
+	add(&base, "FAILS", "passed", 100)
+	add(&target, "FAILS", "failed", 100)
+	add(&base, "FIXED", "failed", 100)
+	add(&target, "FIXED", "passed", 100)
+	add(&base, "UNBLOCKED", "blocked", 0)
+	add(&target, "UNBLOCKED", "passed", 100)
+	add(&base, "GONE", "passed", 100)
+	add(&target, "NEW", "failed", 100)
+	add(&base, "SLOWER", "passed", 100)
+	add(&target, "SLOWER", "passed", 200) // +100%
+	add(&base, "MUCH_SLOWER", "passed", 100)
+	add(&target, "MUCH_SLOWER", "passed", 400) // +300%, listed first
+	add(&base, "FLOOR", "passed", 20)
+	add(&target, "FLOOR", "passed", 40) // +100% and exactly 20 ms: reported
+	add(&base, "NOISE", "passed", 20)
+	add(&target, "NOISE", "passed", 39) // +95% but only 19 ms: ignored
+	add(&base, "BELOW", "passed", 100)
+	add(&target, "BELOW", "passed", 149) // +49%: below the threshold
+	add(&base, "FASTER", "passed", 200)
+	add(&target, "FASTER", "passed", 50) // -75%
+	add(&base, "NO_BASELINE", "passed", 0)
+	add(&target, "NO_BASELINE", "passed", 100)
+	add(&base, "STILL_FAILING", "failed", 100)
+	add(&target, "STILL_FAILING", "failed", 500)
+	add(&base, "RETRIED", "passed", 100)
+	add(&target, "RETRIED", "passed", 100)
+	add(&target, "RETRIED", "failed", 100) // The latest result of a test counts
+
+	c := CompareResults(base, target, DefaultLatencyThreshold)
+	ids := func(changes []TestChange) []string {
+		out := []string{}
+		for _, ch := range changes {
+			out = append(out, ch.TestID)
+		}
+		return out
+	}
+	tests := []struct {
+		name    string
+		changes []TestChange
+		want    []string
+	}{
+		{"newly failed", c.NewlyFailed, []string{"FAILS", "RETRIED"}},
+		{"newly passed", c.NewlyPassed, []string{"FIXED", "UNBLOCKED"}},
+		{"added", c.Added, []string{"NEW"}},
+		{"removed", c.Removed, []string{"GONE"}},
+		{"slower", c.Slower, []string{"MUCH_SLOWER", "FLOOR", "SLOWER"}},
+		{"faster", c.Faster, []string{"FASTER"}},
+	}
+	for _, tt := range tests {
+		if got := ids(tt.changes); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if c.Unchanged != 4 { // NOISE, BELOW, NO_BASELINE, STILL_FAILING
+		t.Errorf("Unchanged = %d, want 4", c.Unchanged)
+	}
+	if c.Regressions() != 5 || !c.HasChanges() {
+		t.Errorf("Regressions() = %d, HasChanges() = %t, want 5 and true", c.Regressions(), c.HasChanges())
+	}
+
+	wantFailure := TestChange{TestID: "FAILS", TestCase: "FAILS", Kind: ChangeNewlyFailed, BaseStatus: "passed", TargetStatus: "failed", BaseMs: 100, TargetMs: 100, Message: "failed FAILS"}
+	if c.NewlyFailed[0] != wantFailure {
+		t.Errorf("NewlyFailed[0] = %+v, want %+v", c.NewlyFailed[0], wantFailure)
+	}
+	wantSlower := TestChange{TestID: "MUCH_SLOWER", TestCase: "MUCH_SLOWER", Kind: ChangeSlower, BaseStatus: "passed", TargetStatus: "passed", BaseMs: 100, TargetMs: 400, LatencyChange: 3}
+	if c.Slower[0] != wantSlower {
+		t.Errorf("Slower[0] = %+v, want %+v", c.Slower[0], wantSlower)
+	}
+	if removed := c.Removed[0]; removed.BaseStatus != "passed" || removed.TargetStatus != "" {
+		t.Errorf("Removed[0] = %+v, want only the base status", removed)
+	}
+
+	same := CompareResults(base, base, DefaultLatencyThreshold)
+	if same.HasChanges() || same.Unchanged != len(base) || same.Slower == nil {
+		t.Errorf("CompareResults(base, base) = %+v, want no changes and empty lists", same)
+	}
+}
+
+func TestCompareRuns(t *testing.T) {
+	store := useTestDB(t)
+	first := saveRun(t, store, db.Run{SuiteFile: "suite.csv"}, [2]string{"A", "passed"}, [2]string{"B", "passed"})
+	second := saveRun(t, store, db.Run{SuiteFile: "suite.csv"}, [2]string{"A", "failed"}, [2]string{"C", "passed"})
+
+	c, err := CompareRuns(first, second, DefaultLatencyThreshold)
+	if err != nil {
+		t.Fatalf("CompareRuns() error = %v", err)
+	}
+	if c.Base.ID != first || c.Target.ID != second || c.Base.SuiteFile != "suite.csv" {
+		t.Errorf("compared runs = %d and %d, want %d and %d", c.Base.ID, c.Target.ID, first, second)
+	}
+	if len(c.NewlyFailed) != 1 || len(c.Removed) != 1 || len(c.Added) != 1 {
+		t.Errorf("CompareRuns() = %+v, want A newly failed, B removed and C added", c)
+	}
+
+	for _, ids := range [][2]int64{{first, 99}, {99, second}} {
+		if _, err := CompareRuns(ids[0], ids[1], DefaultLatencyThreshold); err == nil {
+			t.Errorf("CompareRuns(%d, %d) error = nil, want an error for the missing run", ids[0], ids[1])
+		}
+	}
+}
+
+func TestPreviousRun(t *testing.T) {
+	runs := []db.Run{
+		{ID: 7, SuiteFile: "a.csv", Environment: "staging"}, // Later than the run under review
+		{ID: 2, SuiteFile: "a.csv", Environment: "staging"},
+		{ID: 4, SuiteFile: "a.csv", Environment: "staging"},
+		{ID: 5, SuiteFile: "a.csv", Environment: "production"},
+		{ID: 3, SuiteFile: "b.csv", Environment: "staging"},
+	}
+	tests := []struct {
+		name   string
+		run    db.Run
+		wantID int64
+		wantOK bool
+	}{
+		{"latest earlier run", db.Run{ID: 6, SuiteFile: "a.csv", Environment: "staging"}, 4, true},
+		{"previous run of the same environment", db.Run{ID: 6, SuiteFile: "a.csv", Environment: "production"}, 5, true},
+		{"no earlier run", db.Run{ID: 2, SuiteFile: "a.csv", Environment: "staging"}, 0, false},
+		{"earlier run in another environment only", db.Run{ID: 5, SuiteFile: "b.csv", Environment: "production"}, 0, false},
+		{"other suite", db.Run{ID: 6, SuiteFile: "c.csv", Environment: "staging"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := PreviousRun(tt.run, runs)
+			if ok != tt.wantOK || got.ID != tt.wantID {
+				t.Errorf("PreviousRun() = %d, %t, want %d, %t", got.ID, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package analysis computes statistics over the results stored in the history database.
+package analysis
+
+import (
+	"go-api-testing/internal/db"
+	"sort"
+)
+
+const (
+	DefaultFlakyRuns      = 20  // Number of recent runs analysed by default.
+	DefaultFlakyThreshold = 0.3 // Flakiness score from which a test is flagged by default.
+	minFlakySamples       = 3   // Minimum number of executions needed to judge a test.
+)
+
+// Stability summarizes how consistently a test has behaved over recent runs.
+type Stability struct {
+	TestID       string   `json:"test_id"`
+	TestCase     string   `json:"test_case"`
+	Executions   int      `json:"executions"`    // Results considered (blocked ones excluded).
+	Passed       int      `json:"passed"`        // Executions that passed.
+	Failed       int      `json:"failed"`        // Executions that failed.
+	PassRate     float64  `json:"pass_rate"`     // Passed / Executions, between 0 and 1.
+	Flips        int      `json:"flips"`         // Number of pass/fail transitions between consecutive executions.
+	Flakiness    float64  `json:"flakiness"`     // Flips / (Executions - 1), between 0 and 1.
+	StreakStatus string   `json:"streak_status"` // Status of the latest execution (passed or failed).
+	Streak       int      `json:"streak"`        // Number of consecutive latest executions with StreakStatus.
+	Statuses     []string `json:"statuses"`      // Statuses of the executions, oldest first.
+	Flaky        bool     `json:"flaky"`         // Whether Flakiness reached the threshold.
+}
+
+// Stabilities computes the stability of every test found in the history entries.
+// A test is flagged as flaky when it has at least three executions, has both
+// passed and failed, and its flakiness score reaches the threshold.
+//
+// Parameters:
+//   - history ([]db.HistoryEntry): The results to analyse, in any order.
+//   - threshold (float64): Flakiness score from which a test is flagged, between 0 and 1.
+//
+// Returns:
+//   - []Stability: One entry per test, flaky tests first, then by decreasing flakiness and TestId.
+func Stabilities(history []db.HistoryEntry, threshold float64) []Stability {
+	sorted := append([]db.HistoryEntry(nil), history...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	byTest := make(map[string]*Stability)
+	var order []string
+	for _, e := range sorted {
+		status := entryStatus(e)
+		if status != "passed" && status != "failed" {
+			continue
+		}
+
+		s, ok := byTest[e.TestID]
+		if !ok {
+			s = &Stability{TestID: e.TestID}
+			byTest[e.TestID] = s
+			order = append(order, e.TestID)
+		}
+		s.TestCase = e.TestCase
+		if n := len(s.Statuses); n > 0 && s.Statuses[n-1] != status {
+			s.Flips++
+		}
+		if status == s.StreakStatus {
+			s.Streak++
+		} else {
+			s.StreakStatus, s.Streak = status, 1
+		}
+		if status == "passed" {
+			s.Passed++
+		} else {
+			s.Failed++
+		}
+		s.Statuses = append(s.Statuses, status)
+		s.Executions++
+	}
+
+	stabilities := make([]Stability, 0, len(order))
+	for _, id := range order {
+		s := byTest[id]
+		s.PassRate = float64(s.Passed) / float64(s.Executions)
+		if s.Executions > 1 {
+			s.Flakiness = float64(s.Flips) / float64(s.Executions-1)
+		}
+		s.Flaky = s.Executions >= minFlakySamples && s.Passed > 0 && s.Failed > 0 && s.Flakiness >= threshold
+		stabilities = append(stabilities, *s)
+	}
+	sort.SliceStable(stabilities, func(i, j int) bool {
+		a, b := stabilities[i], stabilities[j]
+		if a.Flaky != b.Flaky {
+			return a.Flaky
+		}
+		if a.Flakiness != b.Flakiness {
+			return a.Flakiness > b.Flakiness
+		}
+		return a.TestID < b.TestID
+	})
+	return stabilities
+}
+
+// FlakyTests analyses the results of the last runs stored in the history database
+// and returns only the tests flagged as flaky.
+//
+// Parameters:
+//   - runs (int): Number of recent runs to analyse.
+//   - threshold (float64): Flakiness score from which a test is flagged, between 0 and 1.
+//
+// Returns:
+//   - []Stability: The flaky tests, most flaky first.
+//   - error: An error if the history cannot be read.
+func FlakyTests(runs int, threshold float64) ([]Stability, error) {
+	history, err := db.Default.QueryHistory(db.HistoryFilter{LastRuns: runs})
+	if err != nil {
+		return nil, err
+	}
+	var flaky []Stability
+	for _, s := range Stabilities(history, threshold) {
+		if s.Flaky {
+			flaky = append(flaky, s)
+		}
+	}
+	return flaky, nil
+}
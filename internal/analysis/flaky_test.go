@@ -0,0 +1,145 @@
+package analysis
+
+import (
+	"go-api-testing/internal/db"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// useTestDB replaces the default store with an empty SQLite history until the test ends.
+func useTestDB(t *testing.T) db.Store {
+	t.Helper()
+	store, err := db.OpenSQLite(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	saved := db.Default
+	db.Default = store
+	t.Cleanup(func() {
+		db.Default = saved
+		store.Close()
+	})
+	return store
+}
+
+// saveRun records a run with one result per test, given as TestId and status.
+func saveRun(t *testing.T, store db.Store, run db.Run, results ...[2]string) int64 {
+	t.Helper()
+	if run.StartTime.IsZero() {
+		run.StartTime = time.Now()
+	}
+	id, err := store.StartRun(run)
+	if err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	for _, r := range results {
+		if _, err := store.SaveResult(id, db.Result{TestID: r[0], TestCase: r[0], Passed: r[1] == "passed", Status: r[1], Duration: 100 * time.Millisecond}); err != nil {
+			t.Fatalf("SaveResult() error = %v", err)
+		}
+	}
+	return id
+}
+
+func TestStabilities(t *testing.T) {
+	var history []db.HistoryEntry
+	add := func(testID string, statuses ...string) {
+		for _, status := range statuses {
+			e := db.HistoryEntry{ID: int64(len(history) + 1), TestID: testID, TestCase: testID, Status: status}
+			if status == "legacy-passed" || status == "legacy-failed" {
+				// Saved before statuses existed: only the boolean result is known
+				e.Status, e.Result = "", status == "legacy-passed"
+			}
+			history = append(history, e)
+		}
+	}
+	add("FLIP", "passed", "failed", "passed")
+	add("STREAK", "passed", "passed", "failed", "failed", "failed")
+	add("TWO", "passed", "failed")
+	add("BLOCKED", "passed", "blocked", "failed", "blocked", "passed")
+	add("STABLE", "passed", "passed", "passed")
+	add("LEGACY", "legacy-passed", "legacy-failed", "legacy-passed")
+	add("FAILING", "failed", "failed", "failed")
+	add("EDGE", "passed", "passed", "passed", "failed")
+	add("FLIP", "failed")
+	history[len(history)-1].TestCase = "Flip renamed" // The latest name is reported
+	add("SKIPPED", "blocked", "blocked")
+
+	// Shuffle the entries: the analysis orders them by id
+	shuffled := append([]db.HistoryEntry(nil), history[len(history)/2:]...)
+	shuffled = append(shuffled, history[:len(history)/2]...)
+
+	p, f := "passed", "failed"
+	want := []Stability{
+		{TestID: "BLOCKED", TestCase: "BLOCKED", Executions: 3, Passed: 2, Failed: 1, PassRate: 2.0 / 3, Flips: 2, Flakiness: 1, StreakStatus: p, Streak: 1, Statuses: []string{p, f, p}, Flaky: true},
+		{TestID: "FLIP", TestCase: "Flip renamed", Executions: 4, Passed: 2, Failed: 2, PassRate: 0.5, Flips: 3, Flakiness: 1, StreakStatus: f, Streak: 1, Statuses: []string{p, f, p, f}, Flaky: true},
+		{TestID: "LEGACY", TestCase: "LEGACY", Executions: 3, Passed: 2, Failed: 1, PassRate: 2.0 / 3, Flips: 2, Flakiness: 1, StreakStatus: p, Streak: 1, Statuses: []string{p, f, p}, Flaky: true},
+		{TestID: "EDGE", TestCase: "EDGE", Executions: 4, Passed: 3, Failed: 1, PassRate: 0.75, Flips: 1, Flakiness: 1.0 / 3, StreakStatus: f, Streak: 1, Statuses: []string{p, p, p, f}, Flaky: true},
+		{TestID: "TWO", TestCase: "TWO", Executions: 2, Passed: 1, Failed: 1, PassRate: 0.5, Flips: 1, Flakiness: 1, StreakStatus: f, Streak: 1, Statuses: []string{p, f}},
+		{TestID: "STREAK", TestCase: "STREAK", Executions: 5, Passed: 2, Failed: 3, PassRate: 0.4, Flips: 1, Flakiness: 0.25, StreakStatus: f, Streak: 3, Statuses: []string{p, p, f, f, f}},
+		{TestID: "FAILING", TestCase: "FAILING", Executions: 3, Failed: 3, StreakStatus: f, Streak: 3, Statuses: []string{f, f, f}},
+		{TestID: "STABLE", TestCase: "STABLE", Executions: 3, Passed: 3, PassRate: 1, StreakStatus: p, Streak: 3, Statuses: []string{p, p, p}},
+	}
+	got := Stabilities(shuffled, DefaultFlakyThreshold)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Stabilities() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// The threshold is inclusive: EDGE (1 flip in 3 transitions) is only flagged up to 1/3
+	tests := []struct {
+		threshold float64
+		wantFlaky []string
+	}{
+		{0, []string{"BLOCKED", "FLIP", "LEGACY", "EDGE", "STREAK"}},
+		{1.0 / 3, []string{"BLOCKED", "FLIP", "LEGACY", "EDGE"}},
+		{0.34, []string{"BLOCKED", "FLIP", "LEGACY"}},
+		{1, []string{"BLOCKED", "FLIP", "LEGACY"}},
+	}
+	for _, tt := range tests {
+		var flaky []string
+		for _, s := range Stabilities(history, tt.threshold) {
+			if s.Flaky {
+				flaky = append(flaky, s.TestID)
+			}
+		}
+		if !reflect.DeepEqual(flaky, tt.wantFlaky) {
+			t.Errorf("Stabilities(threshold %v) flags %v, want %v", tt.threshold, flaky, tt.wantFlaky)
+		}
+	}
+
+	if empty := Stabilities(nil, DefaultFlakyThreshold); empty == nil || len(empty) != 0 {
+		t.Errorf("Stabilities(nil) = %#v, want an empty list", empty)
+	}
+}
+
+func TestFlakyTests(t *testing.T) {
+	store := useTestDB(t)
+	for _, status := range []string{"passed", "failed", "passed", "failed"} {
+		saveRun(t, store, db.Run{}, [2]string{"A", status}, [2]string{"B", "passed"})
+	}
+
+	tests := []struct {
+		runs int
+		want []string
+	}{
+		{3, []string{"A"}}, // failed, passed, failed
+		{2, nil},           // Two executions are not enough to judge a test
+	}
+	for _, tt := range tests {
+		flaky, err := FlakyTests(tt.runs, DefaultFlakyThreshold)
+		if err != nil {
+			t.Fatalf("FlakyTests(%d) error = %v", tt.runs, err)
+		}
+		var ids []string
+		for _, s := range flaky {
+			ids = append(ids, s.TestID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("FlakyTests(%d) = %v, want %v", tt.runs, ids, tt.want)
+		}
+	}
+}
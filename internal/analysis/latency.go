@@ -0,0 +1,225 @@
+package analysis
+
+import (
+	"go-api-testing/internal/db"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultBaselineRuns = 5 // Number of previous executions forming the rolling latency baseline by default.
+	minBaselineSamples  = 3 // Minimum number of previous executions needed to judge a latency regression.
+)
+
+// LatencyPoint is the latency of a test in one run.
+type LatencyPoint struct {
+	RunID      int64  `json:"run_id"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// LatencyTrend follows the latency of a test over the runs and compares its latest
+// execution with a rolling baseline: the median of its previous passed executions.
+type LatencyTrend struct {
+	TestID     string         `json:"test_id"`
+	TestCase   string         `json:"test_case"`
+	Method     string         `json:"method,omitempty"`
+	Endpoint   string         `json:"endpoint,omitempty"`
+	Points     []LatencyPoint `json:"points"`      // Executed results (blocked ones excluded), oldest first.
+	BaselineMs int64          `json:"baseline_ms"` // Median latency of the previous passed executions, 0 if there are too few.
+	LatestMs   int64          `json:"latest_ms"`   // Latency of the latest execution.
+	Change     float64        `json:"change"`      // (LatestMs - BaselineMs) / BaselineMs, 0 without a baseline.
+	Regressed  bool           `json:"regressed"`   // Whether the latest execution passed and is slower than the baseline beyond the threshold.
+}
+
+// EndpointLatency summarizes the latency distribution of the requests to an endpoint.
+// Resource identifiers in the path are replaced with "{id}" (see endpointPath).
+type EndpointLatency struct {
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+	Tests    int    `json:"tests"`   // Number of distinct tests calling the endpoint.
+	Samples  int    `json:"samples"` // Number of executions measured.
+	P50      int64  `json:"p50_ms"`
+	P90      int64  `json:"p90_ms"`
+	P99      int64  `json:"p99_ms"`
+	Max      int64  `json:"max_ms"`
+}
+
+// LatencyAnalysis gathers the latency trends of the tests and the percentiles per endpoint.
+type LatencyAnalysis struct {
+	BaselineRuns int               `json:"baseline_runs"`
+	Threshold    float64           `json:"threshold"`
+	Trends       []LatencyTrend    `json:"trends"`    // Regressed tests first, then by decreasing latest latency.
+	Endpoints    []EndpointLatency `json:"endpoints"` // Sorted by endpoint and method.
+}
+
+// Regressions returns the trends of the tests whose latency regressed.
+func (a LatencyAnalysis) Regressions() []LatencyTrend {
+	var regressed []LatencyTrend
+	for _, t := range a.Trends {
+		if t.Regressed {
+			regressed = append(regressed, t)
+		}
+	}
+	return regressed
+}
+
+// AnalyseLatency computes the latency trend of every test and the percentiles of
+// every endpoint found in the history entries. Blocked results are ignored, since
+// no request was sent. A test regressed when its latest execution passed and took
+// at least threshold more than the median of its previous baselineRuns passed
+// executions (and at least 20 ms more, so that very fast tests do not produce noise).
+//
+// Parameters:
+//   - history ([]db.HistoryEntry): The results to analyse, in any order.
+//   - baselineRuns (int): Number of previous passed executions forming the baseline.
+//   - threshold (float64): Relative latency increase from which a test regressed (0.5 means 50%).
+//
+// Returns:
+//   - LatencyAnalysis: The trends per test and the percentiles per endpoint.
+func AnalyseLatency(history []db.HistoryEntry, baselineRuns int, threshold float64) LatencyAnalysis {
+	a := LatencyAnalysis{
+		BaselineRuns: baselineRuns,
+		Threshold:    threshold,
+		Trends:       []LatencyTrend{},
+		Endpoints:    []EndpointLatency{},
+	}
+	sorted := append([]db.HistoryEntry(nil), history...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	byTest := make(map[string]*LatencyTrend)
+	var order []string
+	type endpointSamples struct {
+		durations []int64
+		tests     map[string]bool
+	}
+	byEndpoint := make(map[[2]string]*endpointSamples)
+	for _, e := range sorted {
+		status := entryStatus(e)
+		if status == "blocked" {
+			continue
+		}
+
+		t, ok := byTest[e.TestID]
+		if !ok {
+			t = &LatencyTrend{TestID: e.TestID}
+			byTest[e.TestID] = t
+			order = append(order, e.TestID)
+		}
+		t.TestCase = e.TestCase
+		if e.Endpoint != "" {
+			t.Method, t.Endpoint = e.Method, e.Endpoint
+		}
+		t.Points = append(t.Points, LatencyPoint{RunID: e.RunID, Status: status, DurationMs: e.DurationMs})
+
+		if e.Endpoint == "" {
+			continue
+		}
+		key := [2]string{strings.ToUpper(e.Method), endpointPath(e.Endpoint)}
+		samples, ok := byEndpoint[key]
+		if !ok {
+			samples = &endpointSamples{tests: make(map[string]bool)}
+			byEndpoint[key] = samples
+		}
+		samples.durations = append(samples.durations, e.DurationMs)
+		samples.tests[e.TestID] = true
+	}
+
+	for _, id := range order {
+		t := byTest[id]
+		latest := t.Points[len(t.Points)-1]
+		t.LatestMs = latest.DurationMs
+
+		var previous []int64
+		for i := len(t.Points) - 2; i >= 0 && len(previous) < baselineRuns; i-- {
+			if t.Points[i].Status == "passed" {
+				previous = append(previous, t.Points[i].DurationMs)
+			}
+		}
+		if len(previous) >= minBaselineSamples {
+			t.BaselineMs = Percentile(previous, 50)
+		}
+		if t.BaselineMs > 0 {
+			t.Change = float64(t.LatestMs-t.BaselineMs) / float64(t.BaselineMs)
+			t.Regressed = latest.Status == "passed" && t.Change >= threshold && t.LatestMs-t.BaselineMs >= minLatencyChangeMs
+		}
+		a.Trends = append(a.Trends, *t)
+	}
+	sort.SliceStable(a.Trends, func(i, j int) bool {
+		x, y := a.Trends[i], a.Trends[j]
+		if x.Regressed != y.Regressed {
+			return x.Regressed
+		}
+		if x.LatestMs != y.LatestMs {
+			return x.LatestMs > y.LatestMs
+		}
+		return x.TestID < y.TestID
+	})
+
+	for key, samples := range byEndpoint {
+		a.Endpoints = append(a.Endpoints, EndpointLatency{
+			Method:   key[0],
+			Endpoint: key[1],
+			Tests:    len(samples.tests),
+			Samples:  len(samples.durations),
+			P50:      Percentile(samples.durations, 50),
+			P90:      Percentile(samples.durations, 90),
+			P99:      Percentile(samples.durations, 99),
+			Max:      Percentile(samples.durations, 100),
+		})
+	}
+	sort.Slice(a.Endpoints, func(i, j int) bool {
+		if a.Endpoints[i].Endpoint != a.Endpoints[j].Endpoint {
+			return a.Endpoints[i].Endpoint < a.Endpoints[j].Endpoint
+		}
+		return a.Endpoints[i].Method < a.Endpoints[j].Method
+	})
+	return a
+}
+
+// Percentile returns the p-th percentile of the values using the nearest-rank
+// method, so the result is always one of the values.
+//
+// Parameters:
+//   - values ([]int64): The samples, in any order. They are not modified.
+//   - p (float64): The percentile, between 0 and 100.
+//
+// Returns:
+//   - int64: The percentile, or 0 if there are no values.
+func Percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// idSegment matches the path segments that identify a resource: numbers, UUIDs and
+// long hexadecimal identifiers (e.g. MongoDB ObjectIds).
+var idSegment = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// endpointPath removes the query string of an endpoint and replaces the resource
+// identifiers of its path with "{id}", so requests that only differ in their parameters
+// or in the resource they target (e.g. the data rows of a test) are grouped together.
+func endpointPath(endpoint string) string {
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	segments := strings.Split(endpoint, "/")
+	for i, segment := range segments {
+		if idSegment.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
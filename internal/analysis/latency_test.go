@@ -0,0 +1,120 @@
+package analysis
+
+import (
+	"go-api-testing/internal/db"
+	"reflect"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	values := []int64{50, 10, 40, 20, 30}
+	tests := []struct {
+		values []int64
+		p      float64
+		want   int64
+	}{
+		{nil, 50, 0},
+		{[]int64{7}, 99, 7},
+		{values, 0, 10},
+		{values, 20, 10},
+		{values, 21, 20},
+		{values, 50, 30},
+		{values, 90, 50},
+		{values, 100, 50},
+		{[]int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 90, 9},
+	}
+	for _, tt := range tests {
+		if got := Percentile(tt.values, tt.p); got != tt.want {
+			t.Errorf("Percentile(%v, %v) = %d, want %d", tt.values, tt.p, got, tt.want)
+		}
+	}
+	if !reflect.DeepEqual(values, []int64{50, 10, 40, 20, 30}) {
+		t.Errorf("Percentile() modified its input: %v", values)
+	}
+}
+
+func TestEndpointPath(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"/users", "/users"},
+		{"/users?page=2", "/users"},
+		{"/users/42/orders/7?expand=items", "/users/{id}/orders/{id}"},
+		{"/users/3f2504e0-4f89-41d3-9a0c-0305e82c3301", "/users/{id}"},
+		{"/docs/507f1f77bcf86cd799439011", "/docs/{id}"},
+		{"/v2/users/me", "/v2/users/me"},
+		{"/cafe", "/cafe"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := endpointPath(tt.endpoint); got != tt.want {
+			t.Errorf("endpointPath(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyseLatency(t *testing.T) {
+	var history []db.HistoryEntry
+	add := func(runID int64, testID, status string, ms int64, method, endpoint string) {
+		history = append(history, db.HistoryEntry{ID: int64(len(history) + 1), RunID: runID, TestID: testID, TestCase: testID, Status: status, DurationMs: ms, Method: method, Endpoint: endpoint})
+	}
+	// SLOW regresses in run 5; FLAT stays around its baseline; the rows of USER call
+	// /users/{id}; NEW has too few executions for a baseline; BLOCKED sent no request.
+	for run, ms := range []int64{100, 110, 90, 100, 200} {
+		add(int64(run+1), "SLOW", "passed", ms, "get", "/slow")
+		add(int64(run+1), "FLAT", "passed", 100+int64(run), "GET", "/flat?page=1")
+		add(int64(run+1), "USER[1]", "passed", 10, "GET", "/users/1")
+		add(int64(run+1), "USER[2]", "passed", 30, "GET", "/users/2")
+	}
+	add(4, "NEW", "passed", 10, "POST", "/users")
+	add(5, "NEW", "failed", 500, "POST", "/users")
+	add(5, "BLOCKED", "blocked", 0, "DELETE", "/users/1")
+	add(5, "OLD", "passed", 5, "", "") // Saved before endpoints were recorded
+
+	// Shuffle the entries: the analysis orders them by id
+	shuffled := append([]db.HistoryEntry(nil), history[len(history)/2:]...)
+	shuffled = append(shuffled, history[:len(history)/2]...)
+	a := AnalyseLatency(shuffled, DefaultBaselineRuns, 0.5)
+
+	type trend struct {
+		id        string
+		endpoint  string
+		points    int
+		baseline  int64
+		latest    int64
+		regressed bool
+	}
+	var trends []trend
+	for _, tr := range a.Trends {
+		trends = append(trends, trend{tr.TestID, tr.Endpoint, len(tr.Points), tr.BaselineMs, tr.LatestMs, tr.Regressed})
+	}
+	wantTrends := []trend{
+		{"SLOW", "/slow", 5, 100, 200, true},
+		{"NEW", "/users", 2, 0, 500, false},
+		{"FLAT", "/flat?page=1", 5, 101, 104, false},
+		{"USER[2]", "/users/2", 5, 30, 30, false},
+		{"USER[1]", "/users/1", 5, 10, 10, false},
+		{"OLD", "", 1, 0, 5, false},
+	}
+	if !reflect.DeepEqual(trends, wantTrends) {
+		t.Errorf("trends =\n%v\nwant\n%v", trends, wantTrends)
+	}
+	if regressions := a.Regressions(); len(regressions) != 1 || regressions[0].TestID != "SLOW" {
+		t.Errorf("Regressions() = %+v, want SLOW", regressions)
+	}
+
+	wantEndpoints := []EndpointLatency{
+		{Method: "GET", Endpoint: "/flat", Tests: 1, Samples: 5, P50: 102, P90: 104, P99: 104, Max: 104},
+		{Method: "GET", Endpoint: "/slow", Tests: 1, Samples: 5, P50: 100, P90: 200, P99: 200, Max: 200},
+		{Method: "POST", Endpoint: "/users", Tests: 1, Samples: 2, P50: 10, P90: 500, P99: 500, Max: 500},
+		{Method: "GET", Endpoint: "/users/{id}", Tests: 2, Samples: 10, P50: 10, P90: 30, P99: 30, Max: 30},
+	}
+	if !reflect.DeepEqual(a.Endpoints, wantEndpoints) {
+		t.Errorf("endpoints =\n%+v\nwant\n%+v", a.Endpoints, wantEndpoints)
+	}
+
+	if empty := AnalyseLatency(nil, DefaultBaselineRuns, 0.5); empty.Trends == nil || empty.Endpoints == nil || len(empty.Trends) != 0 {
+		t.Errorf("AnalyseLatency(nil) = %+v, want empty, non-nil lists", empty)
+	}
+}
@@ -11,6 +11,32 @@ import (
 	"time"
 )
 
+// transport sends the requests of Send; nil means http.DefaultTransport. See SetTransport.
+var transport http.RoundTripper
+
+// recorder receives the exchanges completed by Send, if set. See SetRecorder.
+var recorder func(Exchange)
+
+// SetTransport changes how Send (and so RealizarSolicitud) delivers its requests, e.g.
+// to a local stand-in of the APIs. It must be called before any request is made.
+//
+// Parameters:
+//   - rt (http.RoundTripper): The transport to use, or nil for http.DefaultTransport.
+func SetTransport(rt http.RoundTripper) {
+	transport = rt
+}
+
+// SetRecorder registers a function called with every exchange completed by Send (and
+// so RealizarSolicitud), i.e. every request that got a response. Send may be called
+// from several goroutines at the same time, so the function must be safe for
+// concurrent use. It must be called before any request is made.
+//
+// Parameters:
+//   - record (func(Exchange)): The function to call, or nil to stop recording.
+func SetRecorder(record func(Exchange)) {
+	recorder = record
+}
+
 // RealizarSolicitud makes an HTTP request using the specified method, URL, and body.
 // It also adds headers and authentication credentials if provided.
 // The function returns the HTTP status code, response body as string, and any error if the request fails.
@@ -29,9 +55,86 @@ import (
 //   - string: Response body as string.
 //   - error: Error if any occurs during the request or response processing.
 func RealizarSolicitud(method, url, body, headers, auth, user, password string) (int, string, error) {
+	exchange, err := Send(method, url, body, headers, auth, user, password)
+	return exchange.Response.StatusCode, exchange.Response.Body, err
+}
+
+// Send makes an HTTP request like RealizarSolicitud, but returns the complete exchange:
+// the request as it was sent (including the headers added for authentication) and the
+// response with its headers.
+//
+// Parameters:
+//   - method (string): HTTP method (e.g., "GET", "POST").
+//   - url (string): URL to send the request.
+//   - body (string): Request body in JSON format, if any.
+//   - headers (string): JSON string representing additional headers to add.
+//   - auth (string): Authentication type ("Bearer" or "Basic").
+//   - user (string): Username or token for authentication.
+//   - password (string): Password for authentication, if needed.
+//
+// Returns:
+//   - Exchange: The request and response. The response is empty if the request could not be sent.
+//   - error: Error if any occurs during the request or response processing.
+func Send(method, url, body, headers, auth, user, password string) (Exchange, error) {
+	exchange := Exchange{Request: Request{Method: method, URL: url, Body: body}}
+
 	// Create HTTP client with timeout
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := &http.Client{Timeout: 15 * time.Second, Transport: transport}
 
+	req, err := newRequest(method, url, body, headers, auth, user, password)
+	if err != nil {
+		return exchange, err
+	}
+	exchange.Request.Headers = req.Header.Clone()
+
+	// Execute HTTP request
+	resp, err := client.Do(req)
+	if err != nil {
+		return exchange, fmt.Errorf("error in HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+	exchange.Response.StatusCode = resp.StatusCode
+	exchange.Response.Headers = resp.Header.Clone()
+
+	// Read the entire response body
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return exchange, fmt.Errorf("error reading response body: %v", err)
+	}
+	exchange.Response.Body = string(bodyBytes)
+
+	if recorder != nil {
+		recorder(exchange)
+	}
+	return exchange, nil
+}
+
+// Prepare builds the request Send would make with the same arguments, without sending
+// it: the headers include those added for authentication.
+//
+// Parameters:
+//   - method (string): HTTP method (e.g., "GET", "POST").
+//   - url (string): URL to send the request.
+//   - body (string): Request body in JSON format, if any.
+//   - headers (string): JSON string representing additional headers to add.
+//   - auth (string): Authentication type ("Bearer" or "Basic").
+//   - user (string): Username or token for authentication.
+//   - password (string): Password for authentication, if needed.
+//
+// Returns:
+//   - Request: The request that would be sent.
+//   - error: Error if the URL or the headers JSON is invalid.
+func Prepare(method, url, body, headers, auth, user, password string) (Request, error) {
+	req, err := newRequest(method, url, body, headers, auth, user, password)
+	if err != nil {
+		return Request{}, err
+	}
+	return Request{Method: method, URL: url, Headers: req.Header.Clone(), Body: body}, nil
+}
+
+// newRequest creates an HTTP request with its body, the headers from JSON and the
+// Authorization header of the authentication type.
+func newRequest(method, url, body, headers, auth, user, password string) (*http.Request, error) {
 	// Create request with body (if any)
 	var reqBody io.Reader
 	if body != "" {
@@ -39,14 +142,14 @@ func RealizarSolicitud(method, url, body, headers, auth, user, password string)
 	}
 	req, err := http.NewRequest(method, url, reqBody)
 	if err != nil {
-		return 0, "", err
+		return nil, err
 	}
 
 	// Add headers from JSON
 	if headers != "" {
 		var headerMap map[string]string
 		if err := json.Unmarshal([]byte(headers), &headerMap); err != nil {
-			return 0, "", fmt.Errorf("error parsing headers JSON: %v", err)
+			return nil, fmt.Errorf("error parsing headers JSON: %v", err)
 		}
 		for key, value := range headerMap {
 			req.Header.Add(key, value)
@@ -60,19 +163,5 @@ func RealizarSolicitud(method, url, body, headers, auth, user, password string)
 		creds := base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
 		req.Header.Add("Authorization", "Basic "+creds)
 	}
-
-	// Execute HTTP request
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, "", fmt.Errorf("error in HTTP request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Read the entire response body
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return resp.StatusCode, "", fmt.Errorf("error reading response body: %v", err)
-	}
-
-	return resp.StatusCode, string(bodyBytes), nil
+	return req, nil
 }
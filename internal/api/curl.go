@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Curl returns a curl command line that sends the same request, to reproduce it
+// outside the tool. When masked, the credentials in the headers, the URL and the body
+// are replaced as in MaskRequest, so the command is safe to share but has to be
+// completed before running it.
+//
+// Parameters:
+//   - req (Request): The request, as returned by Prepare or recorded in an Exchange.
+//   - masked (bool): Whether to mask the credentials.
+//
+// Returns:
+//   - string: The command, with one option per line.
+func Curl(req Request, masked bool) string {
+	if masked {
+		req = MaskRequest(req)
+	}
+	rawURL, headers := req.URL, req.Headers
+
+	// The first line holds the method and the URL, the following ones the headers and the body
+	first := "curl"
+	switch {
+	case req.Method == http.MethodHead:
+		first += " --head"
+	case req.Method != http.MethodGet || req.Body != "":
+		first += " -X " + shellQuote(req.Method)
+	}
+	if strings.ContainsAny(rawURL, "[]{}") {
+		first += " --globoff" // Otherwise curl expands them as URL ranges
+	}
+	args := []string{first + " " + shellQuote(rawURL)}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range headers[name] {
+			args = append(args, "-H "+shellQuote(name+": "+value))
+		}
+	}
+	if req.Body != "" {
+		if headers.Get("Content-Type") == "" {
+			// curl would send the body as a form; the tool sends no Content-Type
+			args = append(args, "-H "+shellQuote("Content-Type:"))
+		}
+		args = append(args, "--data-raw "+shellQuote(req.Body))
+	}
+	return strings.Join(args, " \\\n  ")
+}
+
+// shellSafe matches the words that need no quoting in a POSIX shell.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes a word for a POSIX shell, using single quotes when needed.
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
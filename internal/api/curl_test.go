@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"os/exec"
+	"testing"
+)
+
+func TestCurl(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    Request
+		masked bool
+		want   string
+	}{
+		{"simple GET", Request{Method: "GET", URL: "https://api.test/users?page=2"}, false,
+			"curl 'https://api.test/users?page=2'"},
+		{"headers sorted and body", Request{Method: "POST", URL: "https://api.test/users",
+			Headers: http.Header{"X-Trace": {"a", "b"}, "Content-Type": {"application/json"}}, Body: `{"name":"Ann O'Neil"}`}, false,
+			"curl -X POST https://api.test/users \\\n  -H 'Content-Type: application/json' \\\n  -H 'X-Trace: a' \\\n  -H 'X-Trace: b' \\\n  --data-raw '{\"name\":\"Ann O'\\''Neil\"}'"},
+		{"body without Content-Type", Request{Method: "PUT", URL: "https://api.test/notes/1", Body: "text"}, false,
+			"curl -X PUT https://api.test/notes/1 \\\n  -H Content-Type: \\\n  --data-raw text"},
+		{"GET with a body", Request{Method: "GET", URL: "https://api.test/search", Headers: http.Header{"Content-Type": {"text/plain"}}, Body: "q"}, false,
+			"curl -X GET https://api.test/search \\\n  -H 'Content-Type: text/plain' \\\n  --data-raw q"},
+		{"HEAD", Request{Method: "HEAD", URL: "https://api.test/"}, false, "curl --head https://api.test/"},
+		{"brackets in the URL", Request{Method: "GET", URL: "https://api.test/x?ids[]=1&f={a}"}, false,
+			"curl --globoff 'https://api.test/x?ids[]=1&f={a}'"},
+		{"unmasked credentials", Request{Method: "GET", URL: "https://api.test/x?token=abc", Headers: http.Header{"Authorization": {"Bearer abc"}}}, false,
+			"curl 'https://api.test/x?token=abc' \\\n  -H 'Authorization: Bearer abc'"},
+		{"masked credentials", Request{Method: "POST", URL: "https://api.test/x?token=abc", Headers: http.Header{"Authorization": {"Bearer abc"}, "Content-Type": {"application/json"}},
+			Body: `{"password":"p"}`}, true,
+			"curl -X POST 'https://api.test/x?token=****' \\\n  -H 'Authorization: Bearer ****' \\\n  -H 'Content-Type: application/json' \\\n  --data-raw '{\"password\":\"****\"}'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Curl(tt.req, tt.masked); got != tt.want {
+				t.Errorf("Curl() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	words := []string{"plain", "a b", "it's", `"double" $HOME`, "`cmd` !x", "line\nbreak", "", "'", "tab\there", "ñandú"}
+	for _, word := range words {
+		quoted := shellQuote(word)
+		if word != "" && quoted == word && !shellSafe.MatchString(word) {
+			t.Errorf("shellQuote(%q) is not quoted", word)
+		}
+		// The shell must read the quoted word back unchanged
+		sh, err := exec.LookPath("sh")
+		if err != nil {
+			continue
+		}
+		out, err := exec.Command(sh, "-c", "printf %s "+quoted).Output()
+		if err != nil {
+			t.Fatalf("sh -c printf %s: %v", quoted, err)
+		}
+		if string(out) != word {
+			t.Errorf("shell reads shellQuote(%q) = %s as %q", word, quoted, out)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Request is an HTTP request as it was sent to the server.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+}
+
+// Response is an HTTP response as it was received from the server.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers"`
+	Body       string      `json:"body"`
+}
+
+// Exchange is a request together with the response it got.
+type Exchange struct {
+	Request   Request  `json:"request"`
+	Response  Response `json:"response"`
+	Truncated bool     `json:"truncated"` // Whether a body was shortened by Sanitized.
+}
+
+// secretHeaders lists the (canonical) headers whose values are masked by Sanitized.
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+	"X-Auth-Token":        true,
+	"X-Csrf-Token":        true,
+}
+
+// DefaultSecretFields lists the query parameters and JSON or form body fields whose
+// values are masked by Sanitized, unless SetSecretFields gives another list.
+var DefaultSecretFields = []string{
+	"password", "passwd", "pwd", "secret", "client_secret", "token", "access_token",
+	"refresh_token", "id_token", "api_key", "apikey", "access_key", "secret_key", "private_key",
+}
+
+// secretFields holds the normalized names of the fields masked by Sanitized (see fieldKey).
+var secretFields = fieldSet(DefaultSecretFields)
+
+// SetSecretFields changes the query parameters and body fields whose values are masked by
+// Sanitized (and Curl). Names are compared ignoring case, "_" and "-", so "client_secret"
+// also masks "clientSecret" and "Client-Secret". It must be called before any request is made.
+//
+// Parameters:
+//   - names ([]string): The field names to mask, replacing DefaultSecretFields.
+func SetSecretFields(names []string) {
+	secretFields = fieldSet(names)
+}
+
+// fieldSet returns the set of the normalized names.
+func fieldSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[fieldKey(name)] = true
+	}
+	return set
+}
+
+// fieldKey normalizes a field name: lower case, without "_" and "-".
+func fieldKey(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// mask is the replacement for secret values.
+const mask = "****"
+
+// Sanitized returns a copy of the exchange that is safe to store or display:
+// credentials in headers and in the URL, secret query parameters and secret fields
+// of JSON or form request bodies are masked, and bodies longer than maxBodyBytes are
+// truncated at a character boundary (no limit if maxBodyBytes <= 0).
+//
+// Parameters:
+//   - maxBodyBytes (int): Maximum number of bytes kept from each body.
+//
+// Returns:
+//   - Exchange: The sanitized copy.
+func (e Exchange) Sanitized(maxBodyBytes int) Exchange {
+	out := e
+	out.Request = MaskRequest(e.Request)
+	out.Response.Headers = maskHeaders(e.Response.Headers)
+
+	var truncated bool
+	out.Request.Body, truncated = truncate(out.Request.Body, maxBodyBytes)
+	out.Truncated = out.Truncated || truncated
+	out.Response.Body, truncated = truncate(e.Response.Body, maxBodyBytes)
+	out.Truncated = out.Truncated || truncated
+	return out
+}
+
+// MaskRequest returns a copy of the request with its credentials masked as in
+// Sanitized: secret headers, the password of the URL, secret query parameters and
+// secret fields of JSON or form bodies.
+//
+// Parameters:
+//   - req (Request): The request.
+//
+// Returns:
+//   - Request: The masked copy; the body is not truncated.
+func MaskRequest(req Request) Request {
+	return Request{
+		Method:  req.Method,
+		URL:     maskURL(req.URL),
+		Headers: maskHeaders(req.Headers),
+		Body:    maskBody(req.Body, req.Headers.Get("Content-Type")),
+	}
+}
+
+// maskHeaders copies headers, replacing the values of secret headers.
+// The authentication scheme of Authorization headers (e.g. "Bearer") is kept.
+func maskHeaders(headers http.Header) http.Header {
+	if headers == nil {
+		return nil
+	}
+	out := make(http.Header, len(headers))
+	for name, values := range headers {
+		if !secretHeaders[http.CanonicalHeaderKey(name)] {
+			out[name] = append([]string(nil), values...)
+			continue
+		}
+		for _, value := range values {
+			if scheme, _, ok := strings.Cut(value, " "); ok && strings.HasSuffix(name, "Authorization") {
+				out[name] = append(out[name], scheme+" "+mask)
+			} else {
+				out[name] = append(out[name], mask)
+			}
+		}
+	}
+	return out
+}
+
+// maskURL hides the password of credentials embedded in a URL and the values of its
+// secret query parameters, keeping the order of the parameters.
+func maskURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	changed := false
+	if u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), mask)
+			changed = true
+		}
+	}
+	if query, masked := maskQuery(u.RawQuery); masked {
+		u.RawQuery = query
+		changed = true
+	}
+	if !changed {
+		return raw
+	}
+	return u.String()
+}
+
+// maskQuery replaces the values of the secret parameters of a URL-encoded query,
+// reporting whether there were any.
+func maskQuery(query string) (string, bool) {
+	if query == "" {
+		return query, false
+	}
+	params := strings.Split(query, "&")
+	masked := false
+	for i, param := range params {
+		name, _, hasValue := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil && hasValue && secretFields[fieldKey(unescaped)] {
+			params[i] = name + "=" + mask
+			masked = true
+		}
+	}
+	return strings.Join(params, "&"), masked
+}
+
+// maskBody replaces the values of the secret fields of a JSON or form-encoded body,
+// at any depth for JSON. Other bodies, and bodies without secrets, are returned as is.
+func maskBody(body, contentType string) string {
+	if strings.Contains(strings.ToLower(contentType), "application/x-www-form-urlencoded") {
+		masked, _ := maskQuery(body)
+		return masked
+	}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber() // Keep numbers as they were written
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() || !maskJSON(value) {
+		return body
+	}
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return body
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// maskJSON replaces in place the values of the secret fields of a decoded JSON value,
+// reporting whether there were any.
+func maskJSON(value interface{}) bool {
+	masked := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if secretFields[fieldKey(key)] {
+				if child != nil {
+					v[key] = mask
+					masked = true
+				}
+				continue
+			}
+			masked = maskJSON(child) || masked
+		}
+	case []interface{}:
+		for _, child := range v {
+			masked = maskJSON(child) || masked
+		}
+	}
+	return masked
+}
+
+// truncate shortens s to at most max bytes without splitting a UTF-8 character,
+// reporting whether it did.
+func truncate(s string, max int) (string, bool) {
+	if max <= 0 || len(s) <= max {
+		return s, false
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + fmt.Sprintf("\n... [truncated %d bytes]", len(s)-cut), true
+}
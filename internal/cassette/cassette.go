@@ -0,0 +1,108 @@
+// Package cassette records the HTTP exchanges of a test run into cassette files and
+// replays them from a local stand-in of the APIs, so that suites can run offline.
+package cassette
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api-testing/internal/api"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cassette is the set of exchanges recorded during a run, as stored in a JSON file.
+type Cassette struct {
+	RecordedAt   time.Time      `json:"recorded_at"`  // When the recording finished.
+	Seed         int64          `json:"seed"`         // Seed of the generated values of the recorded run.
+	Interactions []api.Exchange `json:"interactions"` // Exchanges in the order they completed.
+}
+
+// Load reads a cassette file.
+//
+// Parameters:
+//   - path (string): The path of the cassette file.
+//
+// Returns:
+//   - *Cassette: The cassette.
+//   - error: An error if the file cannot be read or is not a cassette.
+func Load(path string) (*Cassette, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cassette: %v", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, fmt.Errorf("error parsing cassette %s: %v", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to a file as indented JSON, creating its directory if needed.
+//
+// Parameters:
+//   - path (string): The path of the cassette file.
+//
+// Returns:
+//   - error: An error if the file cannot be written.
+func (c *Cassette) Save(path string) error {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding cassette: %v", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creating cassette directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing cassette: %v", err)
+	}
+	return nil
+}
+
+// Recorder collects the exchanges of a run. Its Record method is meant for
+// api.SetRecorder and may be called from several goroutines at the same time.
+type Recorder struct {
+	mu      sync.Mutex
+	seed    int64
+	records []api.Exchange
+}
+
+// NewRecorder creates an empty recorder.
+//
+// Parameters:
+//   - seed (int64): The seed of the generated values of the run, needed to replay it.
+//
+// Returns:
+//   - *Recorder: The recorder.
+func NewRecorder(seed int64) *Recorder {
+	return &Recorder{seed: seed}
+}
+
+// Record adds an exchange to the recording. Credentials are masked as in reports, so
+// that cassettes can be committed; bodies are kept whole.
+//
+// Parameters:
+//   - exchange (api.Exchange): The exchange to record.
+func (r *Recorder) Record(exchange api.Exchange) {
+	exchange = exchange.Sanitized(0)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, exchange)
+}
+
+// Cassette returns the exchanges recorded so far.
+//
+// Returns:
+//   - *Cassette: The cassette, dated now.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{
+		RecordedAt:   time.Now().UTC(),
+		Seed:         r.seed,
+		Interactions: append([]api.Exchange(nil), r.records...),
+	}
+}
@@ -0,0 +1,100 @@
+package cassette
+
+import (
+	"go-api-testing/internal/api"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     [3]string // Method, URL and body of two requests.
+		wantSame bool
+	}{
+		{"host is ignored", [3]string{"GET", "https://api.test/users", ""}, [3]string{"get", "http://127.0.0.1:8080/users", ""}, true},
+		{"query order is ignored", [3]string{"GET", "/x?b=2&a=1", ""}, [3]string{"GET", "/x?a=1&b=2", ""}, true},
+		{"empty path is the root", [3]string{"GET", "https://api.test", ""}, [3]string{"GET", "https://api.test/", ""}, true},
+		{"JSON formatting is ignored", [3]string{"POST", "/x", `{"b": 1, "a": [1, 2]}`}, [3]string{"POST", "/x", "{\"a\":[1,2],\"b\":1}\n"}, true},
+		{"method differs", [3]string{"GET", "/x", ""}, [3]string{"DELETE", "/x", ""}, false},
+		{"path differs", [3]string{"GET", "/users/1", ""}, [3]string{"GET", "/users/2", ""}, false},
+		{"query value differs", [3]string{"GET", "/x?a=1", ""}, [3]string{"GET", "/x?a=2", ""}, false},
+		{"body differs", [3]string{"POST", "/x", `{"a": 1}`}, [3]string{"POST", "/x", `{"a": 2}`}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := key(tt.a[0], tt.a[1], tt.a[2])
+			if err != nil {
+				t.Fatalf("key(%q) error = %v", tt.a, err)
+			}
+			b, err := key(tt.b[0], tt.b[1], tt.b[2])
+			if err != nil {
+				t.Fatalf("key(%q) error = %v", tt.b, err)
+			}
+			if (a == b) != tt.wantSame {
+				t.Errorf("key(%q) = %q, key(%q) = %q, same = %t, want %t", tt.a, a, tt.b, b, a == b, tt.wantSame)
+			}
+		})
+	}
+	if _, err := key("GET", "http://[::1", ""); err == nil {
+		t.Errorf("key() with an invalid URL error = nil, want an error")
+	}
+}
+
+func TestCanonicalBody(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"", ""},
+		{` {"b": 1, "a": {"d": true, "c": null}} `, `{"a":{"c":null,"d":true},"b":1}`},
+		{`[1.50, 10000000000000000001]`, `[1.50,10000000000000000001]`},
+		{`{"html": "<b>&</b>"}`, `{"html":"<b>&</b>"}`},
+		{"  name=ann&page=1\n", "name=ann&page=1"},
+		{`{"a": 1} {"b": 2}`, `{"a": 1} {"b": 2}`},
+		{`{"a": `, `{"a":`},
+	}
+	for _, tt := range tests {
+		if got := canonicalBody(tt.body); got != tt.want {
+			t.Errorf("canonicalBody(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestRecorderSaveLoad(t *testing.T) {
+	r := NewRecorder(42)
+	r.Record(api.Exchange{
+		Request:  api.Request{Method: "POST", URL: "https://api.test/login?api_key=k", Headers: map[string][]string{"Authorization": {"Bearer t"}}, Body: `{"password": "p"}`},
+		Response: api.Response{StatusCode: 200, Body: strings.Repeat("x", 100000)},
+	})
+	c := r.Cassette()
+	if c.Seed != 42 || len(c.Interactions) != 1 || time.Since(c.RecordedAt) > time.Minute {
+		t.Fatalf("Cassette() = %+v", c)
+	}
+	req := c.Interactions[0].Request
+	if req.URL != "https://api.test/login?api_key=****" ||
+		req.Headers.Get("Authorization") != "Bearer ****" || req.Body != `{"password":"****"}` {
+		t.Errorf("recorded request is not masked: %+v", req)
+	}
+	if len(c.Interactions[0].Response.Body) != 100000 || c.Interactions[0].Truncated {
+		t.Errorf("recorded response body was truncated")
+	}
+
+	file := filepath.Join(t.TempDir(), "cassettes", "run.json")
+	if err := c.Save(file); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !loaded.RecordedAt.Equal(c.RecordedAt) || !reflect.DeepEqual(loaded.Interactions, c.Interactions) || loaded.Seed != c.Seed {
+		t.Errorf("Load() = %+v, want %+v", loaded, c)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.Contains(err.Error(), "error reading cassette") {
+		t.Errorf("Load(missing) error = %v", err)
+	}
+}
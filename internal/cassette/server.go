@@ -0,0 +1,179 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-api-testing/internal/api"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Server is a local HTTP stand-in of the APIs that answers with the responses of a
+// cassette. A request gets the response of the recorded exchange with the same
+// method, path, query and body, whatever its host; when several exchanges match, they
+// are served in the order they were recorded, repeating the last one.
+// Requests without a recorded exchange get a 501 Not Implemented response.
+type Server struct {
+	URL string // Base URL of the stand-in, e.g. "http://127.0.0.1:53412".
+
+	listener net.Listener
+	server   *http.Server
+
+	mu        sync.Mutex
+	responses map[string][]api.Response // Recorded responses by request key.
+	served    map[string]int            // Number of requests served by request key.
+	misses    []string                  // Requests without a recorded exchange.
+}
+
+// Serve starts a stand-in serving the exchanges of a cassette on a free local port.
+//
+// Parameters:
+//   - c (*Cassette): The recorded exchanges.
+//
+// Returns:
+//   - *Server: The running stand-in; Close stops it.
+//   - error: An error if no local port can be opened.
+func Serve(c *Cassette) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("error starting replay server: %v", err)
+	}
+	s := &Server{
+		URL:       "http://" + listener.Addr().String(),
+		listener:  listener,
+		responses: make(map[string][]api.Response),
+		served:    make(map[string]int),
+	}
+	for _, exchange := range c.Interactions {
+		req := exchange.Request
+		k, err := key(req.Method, req.URL, req.Body)
+		if err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("error loading recorded exchange %s %s: %v", req.Method, req.URL, err)
+		}
+		s.responses[k] = append(s.responses[k], exchange.Response)
+	}
+	s.server = &http.Server{Handler: s}
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+// ServeHTTP answers a request with its recorded response.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error reading request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	// Recorded requests have their credentials masked, so the received ones are masked the same way
+	masked := api.MaskRequest(api.Request{Method: r.Method, URL: r.URL.String(), Headers: r.Header, Body: string(body)})
+	k, err := key(masked.Method, masked.URL, masked.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	responses := s.responses[k]
+	var resp api.Response
+	found := len(responses) > 0
+	if found {
+		resp = responses[min(s.served[k], len(responses)-1)]
+		s.served[k]++
+	} else {
+		s.misses = append(s.misses, r.Method+" "+r.URL.RequestURI())
+	}
+	s.mu.Unlock()
+
+	if !found {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotImplemented)
+		message, _ := json.Marshal(fmt.Sprintf("no recorded exchange for %s %s", r.Method, r.URL.RequestURI()))
+		fmt.Fprintf(w, "{\"error\":%s}\n", message)
+		return
+	}
+	for name, values := range resp.Headers {
+		if http.CanonicalHeaderKey(name) == "Content-Length" {
+			continue // Set by the server for the body actually written
+		}
+		w.Header()[name] = values
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.WriteString(w, resp.Body)
+}
+
+// Transport returns a transport that sends every request to the stand-in instead of
+// its host, to pass to api.SetTransport.
+//
+// Returns:
+//   - http.RoundTripper: The transport.
+func (s *Server) Transport() http.RoundTripper {
+	return redirect{host: s.listener.Addr().String()}
+}
+
+// Misses returns the requests received without a recorded exchange, as "METHOD /path?query".
+//
+// Returns:
+//   - []string: The requests, in the order they were received.
+func (s *Server) Misses() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.misses...)
+}
+
+// Close stops the stand-in.
+//
+// Returns:
+//   - error: An error if the server cannot be stopped.
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// redirect is a transport that sends requests to a fixed local host over plain HTTP.
+type redirect struct {
+	host string
+}
+
+// RoundTrip sends a copy of the request to the local host.
+func (t redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.URL.Scheme, out.URL.Host = "http", t.host
+	return http.DefaultTransport.RoundTrip(out)
+}
+
+// key identifies a request by its method, path, query and body. Query parameters are
+// sorted, and JSON bodies are compared whatever their formatting and key order.
+func key(method, rawURL, body string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %v", rawURL, err)
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return strings.ToUpper(method) + " " + path + "?" + u.Query().Encode() + "\n" + canonicalBody(body), nil
+}
+
+// canonicalBody returns JSON bodies re-encoded compactly with sorted keys, and other
+// bodies without their surrounding whitespace.
+func canonicalBody(body string) string {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return strings.TrimSpace(body)
+	}
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return strings.TrimSpace(body)
+	}
+	return strings.TrimSpace(b.String())
+}
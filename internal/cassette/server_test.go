@@ -0,0 +1,106 @@
+package cassette
+
+import (
+	"fmt"
+	"go-api-testing/internal/api"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// send makes a request through api.Send and returns its status and body.
+func send(t *testing.T, method, url, body, headers, auth, user string) (int, string) {
+	t.Helper()
+	exchange, err := api.Send(method, url, body, headers, auth, user, "")
+	if err != nil {
+		t.Fatalf("Send(%s %s) error = %v", method, url, err)
+	}
+	return exchange.Response.StatusCode, exchange.Response.Body
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Cleanup(func() {
+		api.SetRecorder(nil)
+		api.SetTransport(nil)
+	})
+
+	// Record a run against a live API whose responses change with every call
+	var calls atomic.Int32
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Call", fmt.Sprint(calls.Add(1)))
+		if r.Header.Get("Authorization") != "Bearer live-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"call": %d, "path": %q, "body": %q}`, calls.Load(), r.URL.RequestURI(), body)
+	}))
+	recorder := NewRecorder(7)
+	api.SetRecorder(recorder.Record)
+	type request struct{ method, url, body, token string }
+	requests := []request{
+		{"POST", live.URL + "/login?api_key=live-key", `{"user": "ann", "password": "live-password"}`, "live-token"},
+		{"GET", live.URL + "/users?b=2&a=1", "", "live-token"},
+		{"GET", live.URL + "/users?b=2&a=1", "", "live-token"},
+		{"GET", live.URL + "/private", "", "wrong-token"},
+	}
+	var recorded []string
+	for _, r := range requests {
+		status, body := send(t, r.method, r.url, r.body, "", "Bearer", r.token)
+		recorded = append(recorded, fmt.Sprintf("%d %s", status, body))
+	}
+	api.SetRecorder(nil)
+	live.Close()
+
+	file := filepath.Join(t.TempDir(), "run.json")
+	if err := recorder.Cassette().Save(file); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	c, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// Replay offline, with other secrets and a differently formatted body and query
+	server, err := Serve(c)
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	defer server.Close()
+	api.SetTransport(server.Transport())
+	replays := []request{
+		{"POST", "https://api.example.com/login?api_key=ci-key", `{"password":"ci-password","user":"ann"}`, "ci-token"},
+		{"GET", "https://api.example.com/users?a=1&b=2", "", "ci-token"},
+		{"GET", "https://api.example.com/users?a=1&b=2", "", "ci-token"},
+		{"GET", "https://api.example.com/private", "", "ci-token"},
+	}
+	var replayed []string
+	for _, r := range replays {
+		status, body := send(t, r.method, r.url, r.body, "", "Bearer", r.token)
+		replayed = append(replayed, fmt.Sprintf("%d %s", status, body))
+	}
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("replayed responses =\n%q\nwant the recorded ones\n%q", replayed, recorded)
+	}
+
+	// Repeated requests get the last recording again; unknown ones a 501
+	if status, body := send(t, "GET", "https://api.example.com/users?a=1&b=2", "", "", "", ""); fmt.Sprintf("%d %s", status, body) != recorded[2] {
+		t.Errorf("request repeated once more = %d %s, want %s", status, body, recorded[2])
+	}
+	if status, _ := send(t, "DELETE", "https://api.example.com/users/1", "", "", "", ""); status != http.StatusNotImplemented {
+		t.Errorf("unrecorded request status = %d, want 501", status)
+	}
+	if status, _ := send(t, "POST", "https://api.example.com/login?api_key=ci-key", `{"user": "bob", "password": "p"}`, "", "", ""); status != http.StatusNotImplemented {
+		t.Errorf("request with another body status = %d, want 501", status)
+	}
+	wantMisses := []string{"DELETE /users/1", "POST /login?api_key=ci-key"}
+	if misses := server.Misses(); !reflect.DeepEqual(misses, wantMisses) {
+		t.Errorf("Misses() = %q, want %q", misses, wantMisses)
+	}
+}
@@ -0,0 +1,114 @@
+// Package cli implements the subcommands of the command line tool, such as
+// inspecting the history database. Running the test suite remains the default
+// action when no subcommand is given.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// command is a subcommand of the tool. It receives the arguments that follow its name.
+type command struct {
+	usage    string                    // One-line usage, shown in the help.
+	run      func(args []string) error // Implementation of the command.
+	database Database                  // How the command uses the history database.
+}
+
+// Database tells how a subcommand uses the history database, so that it is opened
+// (and migrated) only for the commands that need it.
+type Database int
+
+const (
+	NoDatabase       Database = iota // The command does not touch the history database.
+	OpenDatabase                     // Open the database, leaving its schema as it is.
+	MigratedDatabase                 // Open the database and apply the pending migrations.
+)
+
+// commands lists the available subcommands by name.
+var commands = map[string]command{}
+
+// IsCommand reports whether name is a known subcommand.
+//
+// Parameters:
+//   - name (string): The first command line argument.
+//
+// Returns:
+//   - bool: true if name is a subcommand, false otherwise.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok || name == "help"
+}
+
+// DatabaseOf tells how a subcommand uses the history database.
+//
+// Parameters:
+//   - name (string): The first command line argument.
+//
+// Returns:
+//   - Database: How the subcommand uses the database, NoDatabase for "help" and unknown names.
+func DatabaseOf(name string) Database {
+	return commands[name].database
+}
+
+// Run executes the subcommand named by the first argument.
+//
+// Parameters:
+//   - args ([]string): The command line arguments, starting with the subcommand name.
+//
+// Returns:
+//   - error: An error if the subcommand is unknown or fails.
+func Run(args []string) error {
+	if len(args) == 0 || args[0] == "help" {
+		fmt.Print(Usage())
+		return nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n%s", args[0], Usage())
+	}
+	return cmd.run(args[1:])
+}
+
+// parseInterspersed parses flags that may appear before, between or after the
+// positional arguments, which the flag package alone stops at.
+//
+// Parameters:
+//   - flags (*flag.FlagSet): The flags of the command.
+//   - args ([]string): The arguments of the command.
+//
+// Returns:
+//   - []string: The positional arguments, in order.
+//   - error: An error if a flag is invalid.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// Usage returns the list of subcommands and how to call them.
+func Usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("Usage:\n  main [flags]         Run the test suite (--tags, --plain, --table, --tap, --markdown, --report-url, --openapi, --record, --replay)\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  main %s\n", commands[name].usage)
+	}
+	return b.String()
+}
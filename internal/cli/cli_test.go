@@ -0,0 +1,35 @@
+package cli
+
+import "testing"
+
+func TestDatabaseOf(t *testing.T) {
+	tests := []struct {
+		name string
+		want Database
+	}{
+		{"import", NoDatabase},
+		{"curl", NoDatabase},
+		{"help", NoDatabase},
+		{"unknown", NoDatabase},
+		{"db", OpenDatabase},
+		{"runs", MigratedDatabase},
+		{"show", MigratedDatabase},
+		{"history", MigratedDatabase},
+		{"flaky", MigratedDatabase},
+		{"compare", MigratedDatabase},
+	}
+	for _, tt := range tests {
+		if got := DatabaseOf(tt.name); got != tt.want {
+			t.Errorf("DatabaseOf(%q) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+	for name := range commands {
+		found := false
+		for _, tt := range tests {
+			found = found || tt.name == name
+		}
+		if !found {
+			t.Errorf("command %q is not covered: add it with the database it needs", name)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-api-testing/internal/analysis"
+	"go-api-testing/internal/db"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+func init() {
+	commands["compare"] = command{
+		usage:    "compare <run> [run]  Compare two runs, or a run with the previous one (--threshold, --json, --fail-on-regression)",
+		run:      compareCommand,
+		database: MigratedDatabase,
+	}
+}
+
+// compareCommand prints the tests that changed between two runs.
+func compareCommand(args []string) error {
+	flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+	threshold := flags.Float64("threshold", analysis.DefaultLatencyThreshold, "relative latency change from which a test is reported (0.5 = 50%)")
+	asJSON := flags.Bool("json", false, "print the comparison as JSON")
+	failOnRegression := flags.Bool("fail-on-regression", false, "exit with an error if a test newly failed or got slower")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 1 || len(positional) > 2 || *threshold < 0 {
+		return fmt.Errorf("usage: compare [flags] <base-run> <target-run>, or compare [flags] <run>")
+	}
+
+	ids := make([]int64, len(positional))
+	for i, arg := range positional {
+		if ids[i], err = strconv.ParseInt(arg, 10, 64); err != nil {
+			return fmt.Errorf("invalid run id %q", arg)
+		}
+	}
+	if len(ids) == 1 {
+		// Compare with the previous run of the same suite and environment
+		target, err := db.Default.GetRun(ids[0])
+		if err != nil {
+			return err
+		}
+		runs, err := db.Default.GetRuns(0)
+		if err != nil {
+			return err
+		}
+		previous, ok := analysis.PreviousRun(target, runs)
+		if !ok {
+			return fmt.Errorf("run %d has no previous run of the same suite and environment", target.ID)
+		}
+		ids = []int64{previous.ID, target.ID}
+	}
+
+	c, err := analysis.CompareRuns(ids[0], ids[1], *threshold)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(c); err != nil {
+			return err
+		}
+	} else {
+		printComparison(c)
+	}
+	if *failOnRegression && c.Regressions() > 0 {
+		return fmt.Errorf("%d regression(s) between run %d and run %d", c.Regressions(), c.Base.ID, c.Target.ID)
+	}
+	return nil
+}
+
+// printComparison prints a table per kind of change, skipping empty ones.
+func printComparison(c analysis.RunComparison) {
+	for _, r := range []db.Run{c.Base, c.Target} {
+		fmt.Printf("Run %d: %s", r.ID, r.StartTime.Local().Format("2006-01-02 15:04:05"))
+		if r.Environment != "" {
+			fmt.Printf(", %s", r.Environment)
+		}
+		if r.GitCommit != "" {
+			fmt.Printf(", commit %s", shortCommit(r.GitCommit))
+		}
+		fmt.Printf(", %d passed, %d failed, %d blocked\n", r.Passed, r.Failed, r.Blocked)
+	}
+
+	sections := []struct {
+		title   string
+		changes []analysis.TestChange
+		latency bool
+	}{
+		{"Newly failed", c.NewlyFailed, false},
+		{"Newly passed", c.NewlyPassed, false},
+		{"Added", c.Added, false},
+		{"Removed", c.Removed, false},
+		{"Slower", c.Slower, true},
+		{"Faster", c.Faster, true},
+	}
+	for _, s := range sections {
+		if len(s.changes) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s (%d)\n", s.title, len(s.changes))
+		table := tablewriter.NewWriter(os.Stdout)
+		if s.latency {
+			table.SetHeader([]string{"TestId", "TestCase", "Before", "After", "Change"})
+		} else {
+			table.SetHeader([]string{"TestId", "TestCase", "Before", "After", "Message"})
+		}
+		for _, ch := range s.changes {
+			if s.latency {
+				table.Append([]string{ch.TestID, ch.TestCase, fmt.Sprintf("%d ms", ch.BaseMs), fmt.Sprintf("%d ms", ch.TargetMs), fmt.Sprintf("%+.0f%%", ch.LatencyChange*100)})
+			} else {
+				table.Append([]string{ch.TestID, ch.TestCase, orDash(ch.BaseStatus), orDash(ch.TargetStatus), firstLine(ch.Message)})
+			}
+		}
+		table.Render()
+	}
+
+	if !c.HasChanges() {
+		fmt.Println("\nNo changes between the runs.")
+	}
+	fmt.Printf("\n%d regression(s), %d newly passed, %d added, %d removed, %d unchanged\n",
+		c.Regressions(), len(c.NewlyPassed), len(c.Added), len(c.Removed), c.Unchanged)
+}
+
+// orDash returns s, or "-" if it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
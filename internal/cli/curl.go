@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"go-api-testing/config"
+	"go-api-testing/internal/api"
+	"go-api-testing/internal/csv"
+	"go-api-testing/internal/dataset"
+	"go-api-testing/internal/templating"
+	"strings"
+)
+
+func init() {
+	commands["curl"] = command{
+		usage: "curl [test-id...]    Print the curl command of each test case, or of the given ones (--mask)",
+		run:   curlCommand,
+	}
+}
+
+// curlCommand prints the request of test cases as curl commands, once their data rows
+// are expanded and their template expressions rendered (set SEED to get the values of
+// a given run). A data-driven test id selects all of its rows.
+func curlCommand(args []string) error {
+	flags := flag.NewFlagSet("curl", flag.ContinueOnError)
+	masked := flags.Bool("mask", false, "mask the credentials of the headers and URLs")
+	ids, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+
+	testCases, err := csv.ReadCSV(config.AppConfig.TestCasesFile)
+	if err != nil {
+		return fmt.Errorf("error reading test cases: %v", err)
+	}
+	testCases, err = dataset.Expand(testCases, templating.NewGenerator(config.AppConfig.Seed))
+	if err != nil {
+		return fmt.Errorf("error expanding data-driven tests: %v", err)
+	}
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	found := make(map[string]bool, len(ids))
+	printed := 0
+	for _, tc := range testCases {
+		base, _, _ := strings.Cut(tc.TestId, "[")
+		if len(ids) > 0 && !wanted[tc.TestId] && !wanted[base] {
+			continue
+		}
+		found[tc.TestId], found[base] = true, true
+
+		req, err := api.Prepare(tc.Method, tc.URL+tc.Endpoint, tc.Body, tc.Headers, tc.Authorization, tc.User, tc.Password)
+		if err != nil {
+			return fmt.Errorf("test %s: %v", tc.TestId, err)
+		}
+		if printed > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("# %s - %s\n%s\n", tc.TestId, tc.TestCase, api.Curl(req, *masked))
+		printed++
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("unknown test id(s): %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"go-api-testing/config"
+	"go-api-testing/internal/db"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+func init() {
+	commands["db"] = command{
+		usage:    "db <migrate|prune>   Maintain the history database (migrate [--status], prune [--keep-runs N] [--keep-days N] [--failures-only-after N] [--vacuum])",
+		run:      dbCommand,
+		database: OpenDatabase,
+	}
+}
+
+// dbCommand groups the maintenance operations on the history database.
+func dbCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing db subcommand\n%s", Usage())
+	}
+	switch args[0] {
+	case "migrate":
+		return migrateCommand(args[1:])
+	case "prune":
+		return pruneCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown db subcommand %q\n%s", args[0], Usage())
+	}
+}
+
+// migrateCommand applies pending migrations or, with --status, lists all of them.
+func migrateCommand(args []string) error {
+	flags := flag.NewFlagSet("db migrate", flag.ContinueOnError)
+	status := flags.Bool("status", false, "list migrations and whether they have been applied")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !*status {
+		applied, err := db.Default.Migrate()
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database schema is up to date.")
+		}
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		return nil
+	}
+
+	// Only read the schema version: listing the migrations must not create or baseline it
+	migrations, err := db.Default.MigrationStatus()
+	if err != nil {
+		return err
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Version", "Name", "Status", "Applied At"})
+	for _, m := range migrations {
+		state, appliedAt := "pending", ""
+		if m.Applied() {
+			state, appliedAt = "applied", m.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		table.Append([]string{strconv.Itoa(m.Version), m.Name, state, appliedAt})
+	}
+	table.Render()
+	return nil
+}
+
+// requireMigrated fails if the database has pending migrations, since the db subcommand
+// does not apply them on its own.
+func requireMigrated() error {
+	migrations, err := db.Default.MigrationStatus()
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if !m.Applied() {
+			return fmt.Errorf("the database schema is not up to date (%04d_%s is pending): run db migrate first", m.Version, m.Name)
+		}
+	}
+	return nil
+}
+
+// pruneCommand deletes the history outside the retention policy and optionally
+// compacts the database file. The policy defaults to the one configured in .env.
+func pruneCommand(args []string) error {
+	flags := flag.NewFlagSet("db prune", flag.ContinueOnError)
+	keepRuns := flags.Int("keep-runs", config.AppConfig.HistoryKeepRuns, "keep only the N most recent runs (0 keeps all)")
+	keepDays := flags.Int("keep-days", config.AppConfig.HistoryKeepDays, "keep only the results of the last N days (0 keeps all)")
+	failuresOnly := flags.Int("failures-only-after", config.AppConfig.HistoryFailuresOnlyAfterDays, "beyond N days, keep only failed results (0 keeps all)")
+	vacuum := flags.Bool("vacuum", false, "compact the database file after pruning")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	policy := db.RetentionPolicy{KeepRuns: *keepRuns, KeepDays: *keepDays, FailuresOnlyAfterDays: *failuresOnly}
+	if *keepRuns < 0 || *keepDays < 0 || *failuresOnly < 0 {
+		return fmt.Errorf("retention values must not be negative")
+	}
+	if policy.IsZero() && !*vacuum {
+		return fmt.Errorf("no retention policy given: set HISTORY_KEEP_RUNS, HISTORY_KEEP_DAYS or HISTORY_FAILURES_ONLY_AFTER_DAYS, or pass flags")
+	}
+
+	if err := requireMigrated(); err != nil {
+		return err
+	}
+	stats, err := db.Default.Prune(policy, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d runs, %d results and %d exchanges.\n", stats.Runs, stats.Results, stats.Exchanges)
+
+	if *vacuum {
+		before, err := db.Default.Size()
+		if err != nil {
+			return err
+		}
+		if err := db.Default.Vacuum(); err != nil {
+			return err
+		}
+		after, err := db.Default.Size()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Database compacted from %d to %d bytes.\n", before, after)
+	}
+	return nil
+}
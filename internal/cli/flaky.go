@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"go-api-testing/internal/analysis"
+	"go-api-testing/internal/db"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+func init() {
+	commands["flaky"] = command{
+		usage:    "flaky [flags]        Detect flaky tests over recent runs (--runs, --threshold, --all)",
+		run:      flakyCommand,
+		database: MigratedDatabase,
+	}
+}
+
+// flakyCommand prints the stability of the tests over the last runs.
+func flakyCommand(args []string) error {
+	flags := flag.NewFlagSet("flaky", flag.ContinueOnError)
+	runs := flags.Int("runs", analysis.DefaultFlakyRuns, "number of recent runs to analyse")
+	threshold := flags.Float64("threshold", analysis.DefaultFlakyThreshold, "flakiness score (flips per execution, 0-1) from which a test is flagged")
+	all := flags.Bool("all", false, "list every test, not only the flaky ones")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *runs < 1 || *threshold < 0 || *threshold > 1 {
+		return fmt.Errorf("--runs must be positive and --threshold between 0 and 1")
+	}
+
+	history, err := db.Default.QueryHistory(db.HistoryFilter{LastRuns: *runs})
+	if err != nil {
+		return err
+	}
+	stabilities := analysis.Stabilities(history, *threshold)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"TestId", "TestCase", "Executions", "Pass Rate", "Flips", "Flakiness", "Streak", "History", "Flaky"})
+	flaky := 0
+	for _, s := range stabilities {
+		if s.Flaky {
+			flaky++
+		} else if !*all {
+			continue
+		}
+		table.Append([]string{
+			s.TestID,
+			s.TestCase,
+			strconv.Itoa(s.Executions),
+			fmt.Sprintf("%.0f%%", s.PassRate*100),
+			strconv.Itoa(s.Flips),
+			fmt.Sprintf("%.2f", s.Flakiness),
+			fmt.Sprintf("%d %s", s.Streak, s.StreakStatus),
+			sparkline(s.Statuses),
+			yesNo(s.Flaky),
+		})
+	}
+	if table.NumLines() > 0 {
+		table.Render()
+	}
+	fmt.Printf("%d flaky test(s) out of %d over the last %d run(s) (threshold %.2f)\n", flaky, len(stabilities), *runs, *threshold)
+	return nil
+}
+
+// sparkline renders statuses as a compact string of ✔ and ✘, oldest first.
+func sparkline(statuses []string) string {
+	var b strings.Builder
+	for _, status := range statuses {
+		if status == "passed" {
+			b.WriteString("✔")
+		} else {
+			b.WriteString("✘")
+		}
+	}
+	return b.String()
+}
+
+// yesNo formats a boolean for tables.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
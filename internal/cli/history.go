@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-api-testing/internal/db"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+func init() {
+	commands["history"] = command{
+		usage:    "history [flags]      Query saved results (--test, --from, --to, --status, --run, --env, --limit, --offset, --json)",
+		run:      historyCommand,
+		database: MigratedDatabase,
+	}
+}
+
+// historyCommand prints the saved results matching the given filters.
+func historyCommand(args []string) error {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	testID := flags.String("test", "", "only results of this TestId")
+	from := flags.String("from", "", "only results saved on or after this date (YYYY-MM-DD or RFC 3339)")
+	to := flags.String("to", "", "only results saved before this date (YYYY-MM-DD or RFC 3339)")
+	status := flags.String("status", "", "only results with this status (passed, failed, blocked)")
+	runID := flags.Int64("run", 0, "only results of this run")
+	environment := flags.String("env", "", "only results of runs against this environment")
+	limit := flags.Int("limit", 50, "maximum number of results (0 for all)")
+	offset := flags.Int("offset", 0, "number of results to skip")
+	asJSON := flags.Bool("json", false, "print the results as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	filter := db.HistoryFilter{
+		TestID:      *testID,
+		Status:      *status,
+		RunID:       *runID,
+		Environment: *environment,
+		Limit:       *limit,
+		Offset:      *offset,
+	}
+	var err error
+	if filter.From, err = parseDate(*from); err != nil {
+		return err
+	}
+	if filter.To, err = parseDate(*to); err != nil {
+		return err
+	}
+	switch filter.Status {
+	case "", "passed", "failed", "blocked":
+	default:
+		return fmt.Errorf("invalid status %q: expected passed, failed or blocked", filter.Status)
+	}
+
+	history, err := db.Default.QueryHistory(filter)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if history == nil {
+			history = []db.HistoryEntry{}
+		}
+		return encoder.Encode(history)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Result", "Run", "Date", "Environment", "TestId", "TestCase", "Status", "Duration", "Message"})
+	for _, e := range history {
+		table.Append([]string{
+			strconv.FormatInt(e.ID, 10),
+			strconv.FormatInt(e.RunID, 10),
+			e.RunDate.Local().Format("2006-01-02 15:04:05"),
+			e.Environment,
+			e.TestID,
+			e.TestCase,
+			e.Status,
+			e.Duration().String(),
+			firstLine(e.Message),
+		})
+	}
+	table.Render()
+	fmt.Printf("%d result(s)\n", len(history))
+	return nil
+}
+
+// parseDate parses a date given on the command line; an empty string yields the zero time.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or RFC 3339", s)
+	}
+	return t, nil
+}
+
+// firstLine returns s up to its first line break.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
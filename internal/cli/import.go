@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"go-api-testing/internal/csv"
+	"go-api-testing/internal/importer"
+	"go-api-testing/models"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+func init() {
+	commands["import"] = command{
+		usage: "import <format> <file> Convert test cases from other tools (postman, openapi, curl, har; --out, --prefix)",
+		run:   importCommand,
+	}
+}
+
+// importArgs holds the parsed arguments of the import command.
+type importArgs struct {
+	files []string         // Positional arguments: the files to convert.
+	env   string           // Environment file with variable values (postman).
+	opts  importer.Options // Options common to every format.
+}
+
+// importFunc converts the files of another tool into test cases.
+type importFunc func(a importArgs) ([]models.TestCase, []importer.Warning, error)
+
+// importers lists the supported source formats by name.
+var importers = map[string]importFunc{
+	"postman": importPostman,
+	"openapi": importOpenAPI,
+	"curl":    importCurl,
+	"har":     importHAR,
+}
+
+// importCommand converts a collection into a test cases file, written to --out or to
+// stdout, and reports on stderr what could not be translated.
+func importCommand(args []string) error {
+	formats := make([]string, 0, len(importers))
+	for name := range importers {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	if len(args) == 0 || importers[args[0]] == nil {
+		return fmt.Errorf("usage: import <%s> [flags] <file>", strings.Join(formats, "|"))
+	}
+	format := args[0]
+
+	var a importArgs
+	flags := flag.NewFlagSet("import "+format, flag.ContinueOnError)
+	out := flags.String("out", "", "test cases file to write (stdout by default)")
+	flags.StringVar(&a.opts.Prefix, "prefix", "TC", "prefix of the generated TestIds")
+	flags.StringVar(&a.env, "env", "", "environment file with the values of the variables (postman)")
+	flags.StringVar(&a.opts.BaseURL, "base-url", "", "base URL of the API, instead of the servers of the document (openapi)")
+	var err error
+	if a.files, err = parseInterspersed(flags, args[1:]); err != nil {
+		return err
+	}
+
+	testCases, warnings, err := importers[format](a)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("error creating test cases file: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := csv.WriteTestCases(w, testCases); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Imported %d test case(s)", len(testCases))
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, " into %s", *out)
+	}
+	if len(warnings) == 0 {
+		fmt.Fprintln(os.Stderr, ".")
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "; %d item(s) could not be fully translated:\n", len(warnings))
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "  - %s\n", warning)
+	}
+	return nil
+}
+
+// importPostman converts a Postman collection, with an optional --env environment.
+func importPostman(a importArgs) ([]models.TestCase, []importer.Warning, error) {
+	if len(a.files) != 1 {
+		return nil, nil, fmt.Errorf("usage: import postman [--env environment.json] [--out file] [--prefix TC] <collection.json>")
+	}
+
+	collection, err := os.Open(a.files[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening Postman collection: %v", err)
+	}
+	defer collection.Close()
+	var environment io.Reader
+	if a.env != "" {
+		file, err := os.Open(a.env)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening Postman environment: %v", err)
+		}
+		defer file.Close()
+		environment = file
+	}
+	return importer.ImportPostman(collection, environment, a.opts)
+}
+
+// importOpenAPI generates a starter suite from an OpenAPI 3 document, in YAML or JSON.
+func importOpenAPI(a importArgs) ([]models.TestCase, []importer.Warning, error) {
+	if len(a.files) != 1 {
+		return nil, nil, fmt.Errorf("usage: import openapi [--base-url url] [--out file] [--prefix TC] <spec.yaml>")
+	}
+
+	spec, err := os.Open(a.files[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening OpenAPI document: %v", err)
+	}
+	defer spec.Close()
+	return importer.ImportOpenAPI(spec, a.opts)
+}
+
+// importCurl converts curl command lines read from a file, or from stdin with "-".
+func importCurl(a importArgs) ([]models.TestCase, []importer.Warning, error) {
+	if len(a.files) != 1 {
+		return nil, nil, fmt.Errorf("usage: import curl [--out file] [--prefix TC] <commands.sh|->")
+	}
+	if a.files[0] == "-" {
+		return importer.ImportCurl(os.Stdin, a.opts)
+	}
+
+	commands, err := os.Open(a.files[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening curl commands: %v", err)
+	}
+	defer commands.Close()
+	return importer.ImportCurl(commands, a.opts)
+}
+
+// importHAR converts the API requests of an HTTP Archive.
+func importHAR(a importArgs) ([]models.TestCase, []importer.Warning, error) {
+	if len(a.files) != 1 {
+		return nil, nil, fmt.Errorf("usage: import har [--out file] [--prefix TC] <file.har>")
+	}
+
+	archive, err := os.Open(a.files[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening HAR file: %v", err)
+	}
+	defer archive.Close()
+	return importer.ImportHAR(archive, a.opts)
+}
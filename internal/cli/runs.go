@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"go-api-testing/internal/db"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+func init() {
+	commands["runs"] = command{
+		usage:    "runs [run-id]        List recent runs, or show the results of one run",
+		run:      runsCommand,
+		database: MigratedDatabase,
+	}
+}
+
+// runsCommand lists the last runs or, given a run id, the results of that run.
+func runsCommand(args []string) error {
+	if len(args) == 0 {
+		return listRuns()
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid run id %q", args[0])
+	}
+	return showRun(id)
+}
+
+// listRuns prints the most recent runs with their totals.
+func listRuns() error {
+	runs, err := db.Default.GetRuns(20)
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Run", "Started", "Duration", "Environment", "Commit", "Total", "Passed", "Failed", "Blocked"})
+	for _, r := range runs {
+		table.Append([]string{
+			strconv.FormatInt(r.ID, 10),
+			r.StartTime.Local().Format("2006-01-02 15:04:05"),
+			r.Duration().Round(time.Millisecond).String(),
+			r.Environment,
+			shortCommit(r.GitCommit),
+			strconv.Itoa(r.Total),
+			strconv.Itoa(r.Passed),
+			strconv.Itoa(r.Failed),
+			strconv.Itoa(r.Blocked),
+		})
+	}
+	table.Render()
+	return nil
+}
+
+// showRun prints the metadata and the results of a run.
+func showRun(id int64) error {
+	run, err := db.Default.GetRun(id)
+	if err != nil {
+		return err
+	}
+	results, err := db.GetRunResults(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Run %d\n", run.ID)
+	fmt.Printf("  Started:     %s\n", run.StartTime.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Duration:    %s\n", run.Duration().Round(time.Millisecond))
+	fmt.Printf("  Environment: %s\n", run.Environment)
+	fmt.Printf("  Suite:       %s (sha256 %s)\n", run.SuiteFile, run.SuiteHash)
+	fmt.Printf("  Commit:      %s\n", run.GitCommit)
+	fmt.Printf("  Host:        %s\n", run.Host)
+	fmt.Printf("  Seed:        %d\n", run.Seed)
+	fmt.Printf("  Totals:      %d total, %d passed, %d failed, %d blocked\n", run.Total, run.Passed, run.Failed, run.Blocked)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Result", "TestId", "TestCase", "Status", "Duration", "Message"})
+	for _, r := range results {
+		table.Append([]string{
+			strconv.FormatInt(r.ID, 10),
+			r.TestID,
+			r.TestCase,
+			r.Status,
+			r.Duration().String(),
+			r.Message,
+		})
+	}
+	table.Render()
+	fmt.Println("Use 'show <result>' to see the request and response of a failed test.")
+	return nil
+}
+
+// shortCommit abbreviates a git commit hash for display.
+func shortCommit(commit string) string {
+	if len(commit) > 10 {
+		return commit[:10]
+	}
+	return commit
+}
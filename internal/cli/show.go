@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"go-api-testing/internal/api"
+	"go-api-testing/internal/db"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+func init() {
+	commands["show"] = command{
+		usage:    "show <result-id>     Show a saved result with its request and response",
+		run:      showCommand,
+		database: MigratedDatabase,
+	}
+}
+
+// showCommand prints a saved result and, for failed tests, the stored exchange.
+func showCommand(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: show <result-id>")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid result id %q", args[0])
+	}
+
+	result, err := db.Default.GetResult(id)
+	if err != nil {
+		return err
+	}
+	exchange, err := db.Default.GetExchange(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Result %d (run %d)\n", result.ID, result.RunID)
+	fmt.Printf("  Test:     %s - %s\n", result.TestID, result.TestCase)
+	fmt.Printf("  Status:   %s\n", result.Status)
+	fmt.Printf("  Duration: %s\n", result.Duration())
+	fmt.Printf("  Date:     %s\n", result.RunDate.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Message:  %s\n", result.Message)
+
+	if exchange == nil {
+		fmt.Println("\nNo request/response stored for this result (only failed tests are stored).")
+		return nil
+	}
+	printExchange(*exchange)
+	return nil
+}
+
+// printExchange writes an exchange in a format close to raw HTTP.
+func printExchange(ex api.Exchange) {
+	fmt.Println("\n--- Request ---")
+	fmt.Printf("%s %s\n", ex.Request.Method, ex.Request.URL)
+	printHeaders(ex.Request.Headers)
+	if ex.Request.Body != "" {
+		fmt.Printf("\n%s\n", ex.Request.Body)
+	}
+
+	fmt.Println("\n--- Response ---")
+	if ex.Response.StatusCode == 0 {
+		fmt.Println("(no response received)")
+		return
+	}
+	fmt.Printf("%d %s\n", ex.Response.StatusCode, http.StatusText(ex.Response.StatusCode))
+	printHeaders(ex.Response.Headers)
+	if ex.Response.Body != "" {
+		fmt.Printf("\n%s\n", ex.Response.Body)
+	}
+	if ex.Truncated {
+		fmt.Println("\n(bodies truncated, see MAX_BODY_BYTES)")
+	}
+}
+
+// printHeaders writes headers sorted by name, one per line.
+func printHeaders(headers http.Header) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range headers[name] {
+			fmt.Printf("%s: %s\n", name, value)
+		}
+	}
+}
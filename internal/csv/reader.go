@@ -3,11 +3,16 @@ package csv
 
 import (
 	"encoding/csv"
+	"fmt"
 	"go-api-testing/models"
 	"os"
 	"strconv"
 )
 
+// requiredColumns is the number of mandatory columns in a test cases file
+// (TestId through ExpectedResponse).
+const requiredColumns = 13
+
 // ReadCSV reads test cases from a CSV file located at the specified path.
 // The function converts each line of the file (except the first header line) into a
 // TestCase structure and returns a slice of these structures.
@@ -26,8 +31,10 @@ func ReadCSV(path string) ([]models.TestCase, error) {
 	}
 	defer file.Close()
 
-	// Create a CSV reader
+	// Create a CSV reader. Optional trailing columns (e.g. DependsOn) may be
+	// omitted, so rows are not required to have the same number of fields.
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
 	// Read all lines from the CSV file
 	records, err := reader.ReadAll()
 	if err != nil {
@@ -36,7 +43,10 @@ func ReadCSV(path string) ([]models.TestCase, error) {
 
 	// Convert CSV records into TestCase structures
 	var testCases []models.TestCase
-	for _, record := range records[1:] { // Skip the first line (headers)
+	for line, record := range records[1:] { // Skip the first line (headers)
+		if len(record) < requiredColumns {
+			return nil, fmt.Errorf("line %d: expected at least %d columns, got %d", line+2, requiredColumns, len(record))
+		}
 		testCases = append(testCases, models.TestCase{
 			TestId:             record[0],
 			TestCase:           record[1],
@@ -51,6 +61,11 @@ func ReadCSV(path string) ([]models.TestCase, error) {
 			Body:               record[10],
 			ExpectedStatusCode: atoi(record[11]),
 			ExpectedResponse:   record[12],
+			DependsOn:          field(record, 13),
+			Data:               field(record, 14),
+			CompareOptions:     field(record, 15),
+			Tags:               field(record, 16),
+			ResponseSchema:     field(record, 17),
 		})
 	}
 
@@ -58,6 +73,22 @@ func ReadCSV(path string) ([]models.TestCase, error) {
 	return testCases, nil
 }
 
+// field returns the value of the column at the given index, or an empty
+// string if the row does not have that many columns.
+//
+// Parameters:
+//   - record ([]string): The CSV row.
+//   - i (int): The zero-based column index.
+//
+// Returns:
+//   - string: The column value, or "" when the column is missing.
+func field(record []string, i int) string {
+	if i < len(record) {
+		return record[i]
+	}
+	return ""
+}
+
 // atoi converts a string to an integer, handling conversion errors.
 //
 // Parameters:
@@ -4,7 +4,10 @@ package csv
 import (
 	"encoding/csv"
 	"fmt"
+	"go-api-testing/models"
+	"io"
 	"os"
+	"strconv"
 )
 
 // WriteResults writes the provided results to a CSV file without escaping quotes or other special characters.
@@ -43,3 +46,40 @@ func WriteResults(results [][]string, filename string) error {
 	// Return nil if everything went well
 	return nil
 }
+
+// testCaseHeader lists the columns of a test cases file, in order.
+var testCaseHeader = []string{
+	"TestId", "TestCase", "Run", "Method", "URL", "Endpoint", "Authorization", "User", "Password", "Headers", "Body",
+	"ExpectedStatusCode", "ExpectedResponse", "DependsOn", "Data", "CompareOptions", "Tags",
+	"ResponseSchema",
+}
+
+// WriteTestCases writes test cases in the format read by ReadCSV, header included.
+//
+// Parameters:
+//   - w (io.Writer): The destination, e.g. a file or os.Stdout.
+//   - testCases ([]models.TestCase): The test cases to write.
+//
+// Returns:
+//   - error: An error in case there is a problem writing the data.
+func WriteTestCases(w io.Writer, testCases []models.TestCase) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(testCaseHeader); err != nil {
+		return fmt.Errorf("error writing test cases: %v", err)
+	}
+	for _, tc := range testCases {
+		record := []string{
+			tc.TestId, tc.TestCase, tc.Run, tc.Method, tc.URL, tc.Endpoint, tc.Authorization, tc.User, tc.Password,
+			tc.Headers, tc.Body, strconv.Itoa(tc.ExpectedStatusCode), tc.ExpectedResponse, tc.DependsOn, tc.Data,
+			tc.CompareOptions, tc.Tags, tc.ResponseSchema,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing test cases: %v", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing test cases: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package dataset expands data-driven test cases into one test case per data row.
+package dataset
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go-api-testing/internal/templating"
+	"go-api-testing/models"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Row is a single entry of a data table, mapping field names to values.
+type Row = map[string]interface{}
+
+// Load reads the data table referenced by a test case's Data column.
+// The source can be an inline JSON array of objects, or the path of a
+// .json file (containing such an array) or a .csv file (whose header row
+// gives the field names).
+//
+// Parameters:
+//   - source (string): Inline JSON list or path to the data file.
+//
+// Returns:
+//   - []Row: The rows of the data table, in order.
+//   - error: An error if the source cannot be read or parsed.
+func Load(source string) ([]Row, error) {
+	source = strings.TrimSpace(source)
+	if strings.HasPrefix(source, "[") {
+		return parseJSON([]byte(source))
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("error reading data file: %v", err)
+	}
+	switch strings.ToLower(filepath.Ext(source)) {
+	case ".json":
+		return parseJSON(content)
+	case ".csv":
+		return parseCSV(string(content))
+	default:
+		return nil, fmt.Errorf("unsupported data file %q: expected .csv or .json", source)
+	}
+}
+
+// Expand replaces every test case that has a Data column with one test case per data row
+// and renders the template expressions of all test cases.
+// Each expansion gets the TestId of the original followed by the 1-based row number
+// (e.g. TC-010[3]) and has its {{row.field}} expressions rendered with that row.
+// Dependencies on an expanded test are rewritten to depend on all of its expansions.
+//
+// Parameters:
+//   - testCases ([]models.TestCase): The test cases as loaded from the CSV file.
+//   - gen (*templating.Generator): Source of the values produced by template functions.
+//
+// Returns:
+//   - []models.TestCase: The rendered test cases, with every data-driven one expanded in place.
+//   - error: An error if a data table cannot be loaded or a field cannot be rendered.
+func Expand(testCases []models.TestCase, gen *templating.Generator) ([]models.TestCase, error) {
+	var expanded []models.TestCase
+	derivedIds := make(map[string][]string)
+
+	for _, tc := range testCases {
+		if strings.TrimSpace(tc.Data) == "" {
+			rendered, err := templating.RenderTestCase(tc, templating.Context{Gen: gen})
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, rendered)
+			continue
+		}
+
+		rows, err := Load(tc.Data)
+		if err != nil {
+			return nil, fmt.Errorf("test %s: %v", tc.TestId, err)
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("test %s: data table is empty", tc.TestId)
+		}
+
+		for i, row := range rows {
+			instance, err := templating.RenderTestCase(tc, templating.Context{Row: row, Gen: gen})
+			if err != nil {
+				return nil, fmt.Errorf("%v (data row %d)", err, i+1)
+			}
+			instance.TestId = fmt.Sprintf("%s[%d]", tc.TestId, i+1)
+			instance.Data = ""
+			derivedIds[tc.TestId] = append(derivedIds[tc.TestId], instance.TestId)
+			expanded = append(expanded, instance)
+		}
+	}
+
+	// Point dependencies on data-driven tests to all of their expansions
+	for i, tc := range expanded {
+		deps := tc.Dependencies()
+		rewritten := make([]string, 0, len(deps))
+		changed := false
+		for _, id := range deps {
+			if ids, ok := derivedIds[id]; ok {
+				rewritten = append(rewritten, ids...)
+				changed = true
+				continue
+			}
+			rewritten = append(rewritten, id)
+		}
+		if changed {
+			expanded[i].DependsOn = strings.Join(rewritten, ";")
+		}
+	}
+	return expanded, nil
+}
+
+// parseJSON decodes a JSON array of objects.
+func parseJSON(content []byte) ([]Row, error) {
+	var rows []Row
+	if err := json.Unmarshal(content, &rows); err != nil {
+		return nil, fmt.Errorf("error parsing JSON data: expected an array of objects: %v", err)
+	}
+	return rows, nil
+}
+
+// parseCSV decodes CSV content whose first line holds the field names.
+func parseCSV(content string) ([]Row, error) {
+	records, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing CSV data: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]Row, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(Row, len(header))
+		for i, name := range header {
+			row[name] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,111 @@
+package dataset
+
+import (
+	"go-api-testing/internal/templating"
+	"go-api-testing/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "users.csv")
+	jsonFile := filepath.Join(dir, "users.JSON")
+	if err := os.WriteFile(csvFile, []byte("id,name\n1,Ann\n2,\"Bob, Jr.\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonFile, []byte(`[{"id":1,"tags":["a"]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		want    []Row
+		wantErr string
+	}{
+		{"inline JSON", ` [{"id":1},{"id":2}]`, []Row{{"id": 1.0}, {"id": 2.0}}, ""},
+		{"CSV file", csvFile, []Row{{"id": "1", "name": "Ann"}, {"id": "2", "name": "Bob, Jr."}}, ""},
+		{"JSON file with upper-case extension", jsonFile, []Row{{"id": 1.0, "tags": []interface{}{"a"}}}, ""},
+		{"inline array of numbers", `[1, 2]`, nil, "expected an array of objects"},
+		{"missing file", filepath.Join(dir, "missing.csv"), nil, "error reading data file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Load(tt.source)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("Load() = %v, want %v", rows, tt.want)
+			}
+		})
+	}
+
+	yamlFile := filepath.Join(dir, "users.yaml")
+	if err := os.WriteFile(yamlFile, []byte("- id: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(yamlFile); err == nil || !strings.Contains(err.Error(), "unsupported data file") {
+		t.Errorf("Load(.yaml) error = %v, want unsupported data file", err)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	cases := []models.TestCase{
+		{TestId: "LOGIN", Run: "Y", Body: `{"user":"{{row.user}}"}`},
+		{TestId: "GET", Run: "Y", Endpoint: "/users/{{row.id}}", DependsOn: "LOGIN", Data: `[{"id":1,"address":{"city":"Lima"}},{"id":"b","address":{"city":"Oslo"}}]`, ExpectedResponse: `{"city":"{{row.address.city}}"}`},
+		{TestId: "AFTER", Run: "Y", DependsOn: "GET; LOGIN", Endpoint: "/plain {{unknown}}"},
+	}
+	cases[0].Data = `[{"user":"ann"}]`
+
+	expanded, err := Expand(cases, templating.NewGenerator(1))
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+
+	type summary struct{ id, endpoint, body, expected, dependsOn, data string }
+	var got []summary
+	for _, tc := range expanded {
+		got = append(got, summary{tc.TestId, tc.Endpoint, tc.Body, tc.ExpectedResponse, tc.DependsOn, tc.Data})
+	}
+	want := []summary{
+		{"LOGIN[1]", "", `{"user":"ann"}`, "", "", ""},
+		{"GET[1]", "/users/1", "", `{"city":"Lima"}`, "LOGIN[1]", ""},
+		{"GET[2]", "/users/b", "", `{"city":"Oslo"}`, "LOGIN[1]", ""},
+		{"AFTER", "/plain {{unknown}}", "", "", "GET[1];GET[2];LOGIN[1]", ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expand() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tc      models.TestCase
+		wantErr string
+	}{
+		{"empty table", models.TestCase{TestId: "T", Data: "[]"}, "test T: data table is empty"},
+		{"missing field", models.TestCase{TestId: "T", Data: `[{"id":1},{"x":2}]`, Endpoint: "/{{row.id}}"}, `field "id" not found in data row (data row 2)`},
+		{"row without table", models.TestCase{TestId: "T", Endpoint: "/{{row.id}}"}, "test case has no data row"},
+		{"invalid table", models.TestCase{TestId: "T", Data: `[{`}, "test T: error parsing JSON data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Expand([]models.TestCase{tt.tc}, templating.NewGenerator(1))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expand() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-api-testing/internal/api"
+)
+
+// SaveExchange stores the request and response of a saved result.
+// The exchange should already be sanitized (see api.Exchange.Sanitized).
+func (s *sqlStore) SaveExchange(resultID int64, ex api.Exchange) error {
+	requestHeaders, err := json.Marshal(ex.Request.Headers)
+	if err != nil {
+		return err
+	}
+	responseHeaders, err := json.Marshal(ex.Response.Headers)
+	if err != nil {
+		return err
+	}
+	_, err = s.exec(`
+		INSERT INTO exchanges (result_id, request_method, request_url, request_headers, request_body,
+		                       response_status, response_headers, response_body, truncated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, resultID, ex.Request.Method, ex.Request.URL, string(requestHeaders), ex.Request.Body,
+		ex.Response.StatusCode, string(responseHeaders), ex.Response.Body, ex.Truncated)
+	if err != nil {
+		return fmt.Errorf("error saving exchange in DB: %v", err)
+	}
+	return nil
+}
+
+// GetExchange retrieves the exchange stored for a result, or nil if there is none
+func (s *sqlStore) GetExchange(resultID int64) (*api.Exchange, error) {
+	var ex api.Exchange
+	var requestHeaders, responseHeaders string
+	err := s.queryRow(`
+		SELECT request_method, request_url, request_headers, request_body,
+		       response_status, response_headers, response_body, truncated
+		FROM exchanges
+		WHERE result_id = ?
+	`, resultID).Scan(&ex.Request.Method, &ex.Request.URL, &requestHeaders, &ex.Request.Body,
+		&ex.Response.StatusCode, &responseHeaders, &ex.Response.Body, &ex.Truncated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving exchange from DB: %v", err)
+	}
+	if err := json.Unmarshal([]byte(requestHeaders), &ex.Request.Headers); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(responseHeaders), &ex.Response.Headers); err != nil {
+		return nil, err
+	}
+	return &ex, nil
+}
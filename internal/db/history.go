@@ -0,0 +1,188 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HistoryEntry is a test result saved in the history database
+type HistoryEntry struct {
+	ID          int64     `json:"id"`
+	RunID       int64     `json:"run_id"` // Zero for results saved before runs existed
+	TestID      string    `json:"test_id"`
+	TestCase    string    `json:"test_case"`
+	Result      bool      `json:"result"`
+	Status      string    `json:"status"` // passed, failed or blocked
+	Message     string    `json:"message"`
+	DurationMs  int64     `json:"duration_ms"`
+	RunDate     time.Time `json:"run_date"`
+	Environment string    `json:"environment"` // Environment of the run, if known
+	Method      string    `json:"method"`      // HTTP method of the request, empty for older results
+	Endpoint    string    `json:"endpoint"`    // Endpoint of the request, empty for older results
+}
+
+// Duration returns the time the test took to execute
+func (e HistoryEntry) Duration() time.Duration {
+	return time.Duration(e.DurationMs) * time.Millisecond
+}
+
+// Result is a test result to save with SaveResult
+type Result struct {
+	TestID   string
+	TestCase string
+	Passed   bool
+	Status   string // passed, failed or blocked
+	Message  string
+	Duration time.Duration
+	Method   string // HTTP method of the request
+	Endpoint string // Endpoint of the request
+}
+
+// HistoryFilter restricts the results returned by QueryHistory; zero values mean "any"
+type HistoryFilter struct {
+	TestID      string    // Exact TestId
+	From        time.Time // Results saved at or after this time
+	To          time.Time // Results saved before this time
+	Status      string    // passed, failed or blocked
+	RunID       int64     // Results of a single run
+	Environment string    // Results of runs against this environment
+	LastRuns    int       // Results of the N most recent runs
+	Limit       int       // Maximum number of results (no limit if <= 0)
+	Offset      int       // Number of results to skip, for pagination
+	Ascending   bool      // Oldest first instead of newest first
+}
+
+// dateLayout matches the prefix of the timestamps stored by SQLite, so dates compare as text
+const dateLayout = "2006-01-02 15:04:05"
+
+// QueryHistory retrieves the saved results that match the filter, newest first by default
+func (s *sqlStore) QueryHistory(f HistoryFilter) ([]HistoryEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if f.TestID != "" {
+		conditions = append(conditions, "r.test_id = ?")
+		args = append(args, f.TestID)
+	}
+	if !f.From.IsZero() {
+		conditions = append(conditions, "r.run_date >= ?")
+		args = append(args, s.dialect.timeArg(f.From))
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, "r.run_date < ?")
+		args = append(args, s.dialect.timeArg(f.To))
+	}
+	if f.Status != "" {
+		conditions = append(conditions, "r.status = ?")
+		args = append(args, f.Status)
+	}
+	if f.RunID != 0 {
+		conditions = append(conditions, "r.run_id = ?")
+		args = append(args, f.RunID)
+	}
+	if f.Environment != "" {
+		conditions = append(conditions, "runs.environment = ?")
+		args = append(args, f.Environment)
+	}
+	if f.LastRuns > 0 {
+		conditions = append(conditions, "r.run_id IN (SELECT id FROM runs ORDER BY id DESC LIMIT ?)")
+		args = append(args, f.LastRuns)
+	}
+
+	query := `
+		SELECT r.id, r.run_id, r.test_id, r.test_case, r.result, r.status, r.message, r.duration_ms, r.run_date,
+		       runs.environment, r.method, r.endpoint
+		FROM test_results r
+		LEFT JOIN runs ON runs.id = r.run_id
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if f.Ascending {
+		query += " ORDER BY r.id ASC"
+	} else {
+		query += " ORDER BY r.id DESC"
+	}
+	if f.Limit > 0 || f.Offset > 0 {
+		var limit interface{} = f.Limit
+		if f.Limit <= 0 {
+			limit = s.dialect.noLimit()
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, f.Offset)
+	}
+
+	rows, err := s.query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving history from DB: %v", err)
+	}
+	defer rows.Close()
+
+	var history []HistoryEntry
+	for rows.Next() {
+		entry, err := scanHistoryEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+	return history, rows.Err()
+}
+
+// SaveResult saves a test result of the given run in the database and returns its id
+func (s *sqlStore) SaveResult(runID int64, r Result) (int64, error) {
+	var id int64
+	err := s.queryRow(`
+		INSERT INTO test_results (run_id, test_id, test_case, result, status, message, duration_ms, run_date, method, endpoint)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, runID, r.TestID, r.TestCase, r.Passed, r.Status, r.Message, r.Duration.Milliseconds(), time.Now(),
+		r.Method, r.Endpoint).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error saving result in DB: %v", err)
+	}
+	return id, nil
+}
+
+// GetResult retrieves a single saved result by id
+func (s *sqlStore) GetResult(id int64) (HistoryEntry, error) {
+	row := s.queryRow(`
+		SELECT r.id, r.run_id, r.test_id, r.test_case, r.result, r.status, r.message, r.duration_ms, r.run_date,
+		       runs.environment, r.method, r.endpoint
+		FROM test_results r
+		LEFT JOIN runs ON runs.id = r.run_id
+		WHERE r.id = ?
+	`, id)
+	entry, err := scanHistoryEntry(row)
+	if err == sql.ErrNoRows {
+		return entry, fmt.Errorf("result %d not found", id)
+	}
+	if err != nil {
+		return entry, fmt.Errorf("error retrieving result from DB: %v", err)
+	}
+	return entry, nil
+}
+
+// scanHistoryEntry reads a row selected by QueryHistory or GetResult
+func scanHistoryEntry(s scanner) (HistoryEntry, error) {
+	var e HistoryEntry
+	var runID, durationMs sql.NullInt64
+	var testID, testCase, status, message, environment, method, endpoint sql.NullString
+	var result sql.NullBool
+	err := s.Scan(&e.ID, &runID, &testID, &testCase, &result, &status, &message, &durationMs, &e.RunDate, &environment, &method, &endpoint)
+	if err != nil {
+		return e, err
+	}
+	e.RunID = runID.Int64
+	e.TestID = testID.String
+	e.TestCase = testCase.String
+	e.Result = result.Bool
+	e.Status = status.String
+	e.Message = message.String
+	e.DurationMs = durationMs.Int64
+	e.Environment = environment.String
+	e.Method = method.String
+	e.Endpoint = endpoint.String
+	return e, nil
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles holds the SQL migrations of each dialect, named
+// migrations/<dialect>/NNNN_description.sql. Every dialect has the same versions, so a
+// schema version means the same schema whatever the database.
+// Migrations are append-only: never edit one that has been released, add a new one instead.
+//
+//go:embed migrations/sqlite/*.sql migrations/postgres/*.sql
+var migrationFiles embed.FS
+
+// Migration is a versioned schema change
+type Migration struct {
+	Version   int
+	Name      string
+	SQL       string
+	AppliedAt time.Time // Zero if the migration is pending
+}
+
+// Applied reports whether the migration has been applied to the database
+func (m Migration) Applied() bool {
+	return !m.AppliedAt.IsZero()
+}
+
+// Migrations returns every known migration in version order, with the time it was
+// applied to the database (if it was). Databases created before migrations were
+// introduced get their existing schema recorded first.
+func (s *sqlStore) Migrations() ([]Migration, error) {
+	migrations, err := loadMigrations(s.dialect.name())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+	if err := s.dialect.baseline(s); err != nil {
+		return nil, err
+	}
+	return migrations, s.readApplied(migrations)
+}
+
+// MigrationStatus returns every known migration in version order, like Migrations, but
+// without writing to the database: when the schema_version table does not exist, every
+// migration is reported as pending, even the ones a legacy SQLite schema already has.
+func (s *sqlStore) MigrationStatus() ([]Migration, error) {
+	migrations, err := loadMigrations(s.dialect.name())
+	if err != nil {
+		return nil, err
+	}
+	exists, err := s.dialect.tableExists(s, "schema_version")
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema version: %v", err)
+	}
+	if !exists {
+		return migrations, nil
+	}
+	return migrations, s.readApplied(migrations)
+}
+
+// readApplied sets the time each migration was applied, as recorded in schema_version
+func (s *sqlStore) readApplied(migrations []Migration) error {
+	rows, err := s.query(`SELECT version, applied_at FROM schema_version`)
+	if err != nil {
+		return fmt.Errorf("error reading schema version: %v", err)
+	}
+	defer rows.Close()
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return err
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range migrations {
+		migrations[i].AppliedAt = applied[migrations[i].Version]
+	}
+	return nil
+}
+
+// Migrate applies all pending migrations in a single transaction, so the schema is
+// either fully upgraded or left untouched. It returns the migrations it applied.
+func (s *sqlStore) Migrate() ([]Migration, error) {
+	migrations, err := s.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if !m.Applied() {
+			pending = append(pending, m)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting migration: %v", err)
+	}
+	defer tx.Rollback()
+	now := time.Now()
+	for i, m := range pending {
+		if _, err := tx.Exec(m.SQL); err != nil {
+			return nil, fmt.Errorf("error applying migration %04d_%s: %v", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(s.rebind(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`), m.Version, m.Name, now); err != nil {
+			return nil, fmt.Errorf("error recording migration %04d_%s: %v", m.Version, m.Name, err)
+		}
+		pending[i].AppliedAt = now
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing migrations: %v", err)
+	}
+	return pending, nil
+}
+
+// loadMigrations reads the embedded migration files of a dialect, sorted by version
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := migrationFiles.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		number, description, ok := strings.Cut(name, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid migration file name %q: expected NNNN_description.sql", entry.Name())
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrations %q and %q share version %d", other, entry.Name(), version)
+		}
+		seen[version] = entry.Name()
+
+		content, err := migrationFiles.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: description, SQL: string(content)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// ensureVersionTable creates the table that records applied migrations
+func (s *sqlStore) ensureVersionTable() error {
+	_, err := s.exec(fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT,
+			applied_at %s
+		)
+	`, s.dialect.timestampType()))
+	if err != nil {
+		return fmt.Errorf("error creating schema_version table: %v", err)
+	}
+	return nil
+}
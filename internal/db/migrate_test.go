@@ -0,0 +1,179 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	sqlite, err := loadMigrations("sqlite")
+	if err != nil {
+		t.Fatalf("loadMigrations(sqlite) error = %v", err)
+	}
+	postgres, err := loadMigrations("postgres")
+	if err != nil {
+		t.Fatalf("loadMigrations(postgres) error = %v", err)
+	}
+	if len(sqlite) == 0 || len(sqlite) != len(postgres) {
+		t.Fatalf("got %d SQLite and %d PostgreSQL migrations, want the same non-zero number", len(sqlite), len(postgres))
+	}
+	for i := range sqlite {
+		if sqlite[i].Version != i+1 {
+			t.Errorf("migration %d has version %d, want consecutive versions from 1", i, sqlite[i].Version)
+		}
+		if sqlite[i].Version != postgres[i].Version || sqlite[i].Name != postgres[i].Name {
+			t.Errorf("SQLite migration %04d_%s differs from PostgreSQL %04d_%s", sqlite[i].Version, sqlite[i].Name, postgres[i].Version, postgres[i].Name)
+		}
+	}
+}
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, dialect string) {
+		store := openEmptyStore(t, dialect)
+
+		migrations, err := store.Migrations()
+		if err != nil {
+			t.Fatalf("Migrations() error = %v", err)
+		}
+		for _, m := range migrations {
+			if m.Applied() {
+				t.Errorf("migration %04d_%s is applied on a new database", m.Version, m.Name)
+			}
+		}
+
+		applied, err := store.Migrate()
+		if err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		if len(applied) != len(migrations) {
+			t.Errorf("Migrate() applied %d migrations, want %d", len(applied), len(migrations))
+		}
+		if again, err := store.Migrate(); err != nil || len(again) != 0 {
+			t.Errorf("second Migrate() = %v, %v; want nothing to apply", again, err)
+		}
+
+		migrations, err = store.Migrations()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range migrations {
+			if !m.Applied() {
+				t.Errorf("migration %04d_%s is pending after Migrate()", m.Version, m.Name)
+			}
+		}
+	})
+}
+
+func TestMigrationStatusReadOnly(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, dialect string) {
+		store := openEmptyStore(t, dialect)
+
+		status, err := store.MigrationStatus()
+		if err != nil {
+			t.Fatalf("MigrationStatus() error = %v", err)
+		}
+		if len(status) == 0 {
+			t.Fatal("MigrationStatus() returned no migrations")
+		}
+		for _, m := range status {
+			if m.Applied() {
+				t.Errorf("migration %04d_%s is applied on a new database", m.Version, m.Name)
+			}
+		}
+		if exists, err := store.dialect.tableExists(store, "schema_version"); err != nil || exists {
+			t.Fatalf("schema_version exists = %t, %v after MigrationStatus(); want it not created", exists, err)
+		}
+
+		if _, err := store.Migrate(); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		status, err = store.MigrationStatus()
+		if err != nil {
+			t.Fatalf("MigrationStatus() error = %v", err)
+		}
+		for _, m := range status {
+			if !m.Applied() {
+				t.Errorf("migration %04d_%s is pending after Migrate()", m.Version, m.Name)
+			}
+		}
+	})
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	migrations, err := loadMigrations("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		legacy       int // Number of migrations whose schema the legacy database already has.
+		firstApplied int // Version of the first migration Migrate must apply.
+	}{
+		{"results table only", 1, 2},
+		{"results grouped into runs", 2, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "legacy.db")
+
+			// Build the schema as older versions did, without recording any version
+			conn, err := sql.Open("sqlite", path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range migrations[:tt.legacy] {
+				if _, err := conn.Exec(m.SQL); err != nil {
+					t.Fatalf("creating legacy schema: %v", err)
+				}
+			}
+			if _, err := conn.Exec(`INSERT INTO test_results (test_id, test_case, result, message, run_date) VALUES ('TC-1', 'old', 1, 'ok', '2024-01-01 10:00:00')`); err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+
+			store, err := OpenSQLite(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+
+			// The read-only status does not record the legacy schema, so it reports everything as pending
+			status, err := store.MigrationStatus()
+			if err != nil {
+				t.Fatalf("MigrationStatus() error = %v", err)
+			}
+			for _, m := range status {
+				if m.Applied() {
+					t.Errorf("MigrationStatus(): migration %04d_%s is applied before the schema version is recorded", m.Version, m.Name)
+				}
+			}
+
+			status, err = store.Migrations()
+			if err != nil {
+				t.Fatalf("Migrations() error = %v", err)
+			}
+			for _, m := range status {
+				if want := m.Version <= tt.legacy; m.Applied() != want {
+					t.Errorf("migration %04d_%s applied = %t, want %t", m.Version, m.Name, m.Applied(), want)
+				}
+			}
+
+			applied, err := store.Migrate()
+			if err != nil {
+				t.Fatalf("Migrate() error = %v", err)
+			}
+			if len(applied) != len(migrations)-tt.legacy || applied[0].Version != tt.firstApplied {
+				t.Fatalf("Migrate() applied %v, want versions %d to %d", applied, tt.firstApplied, len(migrations))
+			}
+
+			history, err := store.QueryHistory(HistoryFilter{})
+			if err != nil {
+				t.Fatalf("QueryHistory() error = %v", err)
+			}
+			if len(history) != 1 || history[0].TestID != "TC-1" || !history[0].Result {
+				t.Errorf("legacy results after migration = %+v, want the TC-1 result kept", history)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+-- Results of every executed test case.
+CREATE TABLE test_results (
+	id BIGSERIAL PRIMARY KEY,
+	test_id TEXT,
+	test_case TEXT,
+	result BOOLEAN,
+	message TEXT,
+	run_date TIMESTAMPTZ
+);
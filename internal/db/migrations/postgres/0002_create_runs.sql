@@ -0,0 +1,18 @@
+-- Group results into runs, one per execution of the suite.
+CREATE TABLE runs (
+	id BIGSERIAL PRIMARY KEY,
+	start_time TIMESTAMPTZ,
+	end_time TIMESTAMPTZ,
+	environment TEXT,
+	suite_file TEXT,
+	suite_hash TEXT,
+	git_commit TEXT,
+	host TEXT,
+	seed BIGINT,
+	total INTEGER DEFAULT 0,
+	passed INTEGER DEFAULT 0,
+	failed INTEGER DEFAULT 0,
+	blocked INTEGER DEFAULT 0
+);
+
+ALTER TABLE test_results ADD COLUMN run_id BIGINT REFERENCES runs(id);
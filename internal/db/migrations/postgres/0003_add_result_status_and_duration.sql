@@ -0,0 +1,13 @@
+-- Store the detailed status (passed, failed, blocked) and the duration of each result.
+ALTER TABLE test_results ADD COLUMN status TEXT;
+ALTER TABLE test_results ADD COLUMN duration_ms BIGINT;
+
+UPDATE test_results
+SET status = CASE
+	WHEN result THEN 'passed'
+	WHEN message LIKE 'Blocked by %' THEN 'blocked'
+	ELSE 'failed'
+END;
+
+CREATE INDEX idx_test_results_run_id ON test_results (run_id);
+CREATE INDEX idx_test_results_test_id ON test_results (test_id);
@@ -0,0 +1,12 @@
+-- Request and response of failed tests, for troubleshooting.
+CREATE TABLE exchanges (
+	result_id BIGINT PRIMARY KEY REFERENCES test_results(id) ON DELETE CASCADE,
+	request_method TEXT,
+	request_url TEXT,
+	request_headers TEXT,
+	request_body TEXT,
+	response_status INTEGER,
+	response_headers TEXT,
+	response_body TEXT,
+	truncated BOOLEAN
+);
@@ -0,0 +1,3 @@
+-- Store the request of each result, so latency can be analysed per endpoint.
+ALTER TABLE test_results ADD COLUMN method TEXT;
+ALTER TABLE test_results ADD COLUMN endpoint TEXT;
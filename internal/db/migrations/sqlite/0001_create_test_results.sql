@@ -0,0 +1,9 @@
+-- Results of every executed test case.
+CREATE TABLE test_results (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	test_id TEXT,
+	test_case TEXT,
+	result BOOLEAN,
+	message TEXT,
+	run_date DATETIME
+);
@@ -0,0 +1,18 @@
+-- Group results into runs, one per execution of the suite.
+CREATE TABLE runs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	start_time DATETIME,
+	end_time DATETIME,
+	environment TEXT,
+	suite_file TEXT,
+	suite_hash TEXT,
+	git_commit TEXT,
+	host TEXT,
+	seed INTEGER,
+	total INTEGER DEFAULT 0,
+	passed INTEGER DEFAULT 0,
+	failed INTEGER DEFAULT 0,
+	blocked INTEGER DEFAULT 0
+);
+
+ALTER TABLE test_results ADD COLUMN run_id INTEGER REFERENCES runs(id);
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetentionPolicy limits how much history is kept; zero values mean "keep everything"
+type RetentionPolicy struct {
+	KeepRuns              int // Keep only the N most recent runs
+	KeepDays              int // Keep only the results of the last N days
+	FailuresOnlyAfterDays int // Beyond N days, keep only failed results
+}
+
+// IsZero reports whether the policy keeps the whole history
+func (p RetentionPolicy) IsZero() bool {
+	return p.KeepRuns <= 0 && p.KeepDays <= 0 && p.FailuresOnlyAfterDays <= 0
+}
+
+// PruneStats counts the rows deleted by Prune
+type PruneStats struct {
+	Runs      int64
+	Results   int64
+	Exchanges int64
+}
+
+// Prune deletes the history that falls outside the policy, in a single transaction.
+// Results saved before runs existed count as older than any run. Days are counted back from now.
+func (s *sqlStore) Prune(p RetentionPolicy, now time.Time) (PruneStats, error) {
+	var stats PruneStats
+	if p.IsZero() {
+		return stats, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return stats, fmt.Errorf("error starting prune transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	exec := func(counter *int64, query string, args ...interface{}) error {
+		res, err := tx.Exec(s.rebind(query), args...)
+		if err != nil {
+			return fmt.Errorf("error pruning history: %v", err)
+		}
+		n, _ := res.RowsAffected()
+		*counter += n
+		return nil
+	}
+	// Exchanges are deleted before their results, whether or not the database enforces foreign keys
+	deleteResults := func(where string, args ...interface{}) error {
+		if err := exec(&stats.Exchanges, "DELETE FROM exchanges WHERE result_id IN (SELECT id FROM test_results WHERE "+where+")", args...); err != nil {
+			return err
+		}
+		return exec(&stats.Results, "DELETE FROM test_results WHERE "+where, args...)
+	}
+
+	if p.KeepRuns > 0 {
+		const recent = "SELECT id FROM runs ORDER BY id DESC LIMIT ?"
+		if err := deleteResults("run_id IS NULL OR run_id NOT IN ("+recent+")", p.KeepRuns); err != nil {
+			return stats, err
+		}
+		if err := exec(&stats.Runs, "DELETE FROM runs WHERE id NOT IN ("+recent+")", p.KeepRuns); err != nil {
+			return stats, err
+		}
+	}
+	if p.KeepDays > 0 {
+		cutoff := s.dialect.timeArg(now.AddDate(0, 0, -p.KeepDays))
+		if err := deleteResults("run_date < ?", cutoff); err != nil {
+			return stats, err
+		}
+		// Runs that started before the cutoff but still have recent results are kept
+		const inUse = "SELECT run_id FROM test_results WHERE run_id IS NOT NULL"
+		if err := exec(&stats.Runs, "DELETE FROM runs WHERE start_time < ? AND id NOT IN ("+inUse+")", cutoff); err != nil {
+			return stats, err
+		}
+	}
+	if p.FailuresOnlyAfterDays > 0 {
+		cutoff := s.dialect.timeArg(now.AddDate(0, 0, -p.FailuresOnlyAfterDays))
+		if err := deleteResults("run_date < ? AND status <> 'failed'", cutoff); err != nil {
+			return stats, err
+		}
+	}
+
+	// Exchanges left behind by older versions, which did not delete them with their results
+	if err := exec(&stats.Exchanges, "DELETE FROM exchanges WHERE result_id NOT IN (SELECT id FROM test_results)"); err != nil {
+		return stats, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return stats, fmt.Errorf("error committing prune: %v", err)
+	}
+	return stats, nil
+}
+
+// Vacuum rebuilds the database to give the space of deleted rows back to the file system
+func (s *sqlStore) Vacuum() error {
+	if _, err := s.exec("VACUUM"); err != nil {
+		return fmt.Errorf("error vacuuming database: %v", err)
+	}
+	return nil
+}
+
+// Size returns the size of the database in bytes
+func (s *sqlStore) Size() (int64, error) {
+	return s.dialect.size(s)
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"go-api-testing/internal/api"
+	"testing"
+	"time"
+)
+
+// seedHistory fills the store with three runs started 40, 10 and 0 days before now, a
+// result saved before runs existed 60 days ago and, on SQLite, which does not enforce
+// foreign keys, an exchange without its result. Each run has a passed result; the first
+// two also have a failed one with an exchange.
+func seedHistory(t *testing.T, s *sqlStore, now time.Time) {
+	t.Helper()
+	addResult := func(runID interface{}, testID, status string, date time.Time) int64 {
+		t.Helper()
+		var id int64
+		err := s.queryRow(`
+			INSERT INTO test_results (run_id, test_id, test_case, result, status, message, run_date)
+			VALUES (?, ?, ?, ?, ?, '', ?)
+			RETURNING id
+		`, runID, testID, testID, status == "passed", status, date).Scan(&id)
+		if err != nil {
+			t.Fatalf("seeding history: %v", err)
+		}
+		return id
+	}
+
+	for _, age := range []int{40, 10, 0} {
+		start := now.AddDate(0, 0, -age)
+		runID, err := s.StartRun(Run{StartTime: start})
+		if err != nil {
+			t.Fatal(err)
+		}
+		addResult(runID, "OK", "passed", start)
+		if age > 0 {
+			failed := addResult(runID, "KO", "failed", start)
+			if err := s.SaveExchange(failed, api.Exchange{Request: api.Request{Method: "GET", URL: "/ko"}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	addResult(nil, "LEGACY", "passed", now.AddDate(0, 0, -60))
+	if s.dialect.name() == "sqlite" {
+		if err := s.SaveExchange(999, api.Exchange{Request: api.Request{Method: "GET", URL: "/gone"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   PruneStats // On SQLite, which also deletes the exchange without its result
+		kept   []string   // TestIds of the remaining results, newest first
+	}{
+		{"keep everything", RetentionPolicy{}, PruneStats{}, []string{"LEGACY", "OK", "KO", "OK", "KO", "OK"}},
+		{"keep runs", RetentionPolicy{KeepRuns: 2}, PruneStats{Runs: 1, Results: 3, Exchanges: 2}, []string{"OK", "KO", "OK"}},
+		{"keep days", RetentionPolicy{KeepDays: 30}, PruneStats{Runs: 1, Results: 3, Exchanges: 2}, []string{"OK", "KO", "OK"}},
+		{"failures only", RetentionPolicy{FailuresOnlyAfterDays: 5}, PruneStats{Results: 3, Exchanges: 1}, []string{"OK", "KO", "KO"}},
+		{"combined", RetentionPolicy{KeepRuns: 2, FailuresOnlyAfterDays: 5}, PruneStats{Runs: 1, Results: 4, Exchanges: 2}, []string{"OK", "KO"}},
+		{"more runs than kept", RetentionPolicy{KeepRuns: 10}, PruneStats{Results: 1, Exchanges: 1}, []string{"OK", "KO", "OK", "KO", "OK"}},
+	}
+	forEachDialect(t, func(t *testing.T, dialect string) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				store := openTestStore(t, dialect)
+				seedHistory(t, store, now)
+
+				stats, err := store.Prune(tt.policy, now)
+				if err != nil {
+					t.Fatalf("Prune() error = %v", err)
+				}
+				want := tt.want
+				if dialect != "sqlite" && !tt.policy.IsZero() {
+					want.Exchanges-- // No exchange without its result to delete
+				}
+				if stats != want {
+					t.Errorf("Prune() = %+v, want %+v", stats, want)
+				}
+
+				history, err := store.QueryHistory(HistoryFilter{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				var kept []string
+				for _, e := range history {
+					kept = append(kept, e.TestID)
+					if e.Status == "failed" {
+						if ex, err := store.GetExchange(e.ID); err != nil || ex == nil {
+							t.Errorf("exchange of kept result %d = %v, %v; want it kept", e.ID, ex, err)
+						}
+					}
+				}
+				if len(kept) != len(tt.kept) {
+					t.Fatalf("kept results %v, want %v", kept, tt.kept)
+				}
+				for i := range kept {
+					if kept[i] != tt.kept[i] {
+						t.Errorf("kept results %v, want %v", kept, tt.kept)
+						break
+					}
+				}
+			})
+		}
+	})
+}
+
+func TestRetentionPolicyIsZero(t *testing.T) {
+	if !(RetentionPolicy{}).IsZero() || !(RetentionPolicy{KeepRuns: -1}).IsZero() {
+		t.Error("IsZero() = false for a policy that keeps everything")
+	}
+	if (RetentionPolicy{KeepDays: 1}).IsZero() {
+		t.Error("IsZero() = true for a policy with KeepDays")
+	}
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Run groups the results of one execution of a test suite
+type Run struct {
+	ID          int64     `json:"id"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Environment string    `json:"environment"`
+	SuiteFile   string    `json:"suite_file"`
+	SuiteHash   string    `json:"suite_hash"` // SHA-256 of the suite file contents
+	GitCommit   string    `json:"git_commit"` // Empty if the suite was not run from a git checkout
+	Host        string    `json:"host"`
+	Seed        int64     `json:"seed"` // Seed of the template value generator
+	Total       int       `json:"total"`
+	Passed      int       `json:"passed"`
+	Failed      int       `json:"failed"`
+	Blocked     int       `json:"blocked"`
+}
+
+// Duration returns how long the run took, or zero if it has not finished
+func (r Run) Duration() time.Duration {
+	if r.EndTime.IsZero() {
+		return 0
+	}
+	return r.EndTime.Sub(r.StartTime)
+}
+
+// StartRun records the beginning of a run and returns its id
+func (s *sqlStore) StartRun(run Run) (int64, error) {
+	var id int64
+	err := s.queryRow(`
+		INSERT INTO runs (start_time, environment, suite_file, suite_hash, git_commit, host, seed)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, run.StartTime, run.Environment, run.SuiteFile, run.SuiteHash, run.GitCommit, run.Host, run.Seed).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error saving run in DB: %v", err)
+	}
+	return id, nil
+}
+
+// FinishRun records the end time and totals of a run
+func (s *sqlStore) FinishRun(run Run) error {
+	_, err := s.exec(`
+		UPDATE runs SET end_time = ?, total = ?, passed = ?, failed = ?, blocked = ?
+		WHERE id = ?
+	`, run.EndTime, run.Total, run.Passed, run.Failed, run.Blocked, run.ID)
+	if err != nil {
+		return fmt.Errorf("error updating run in DB: %v", err)
+	}
+	return nil
+}
+
+// GetRuns retrieves the most recent runs, newest first (all of them if limit <= 0)
+func (s *sqlStore) GetRuns(limit int) ([]Run, error) {
+	query := `
+		SELECT id, start_time, end_time, environment, suite_file, suite_hash, git_commit, host, seed,
+		       total, passed, failed, blocked
+		FROM runs
+		ORDER BY id DESC
+	`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	rows, err := s.query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving runs from DB: %v", err)
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// GetRun retrieves a single run by id
+func (s *sqlStore) GetRun(id int64) (Run, error) {
+	row := s.queryRow(`
+		SELECT id, start_time, end_time, environment, suite_file, suite_hash, git_commit, host, seed,
+		       total, passed, failed, blocked
+		FROM runs
+		WHERE id = ?
+	`, id)
+	run, err := scanRun(row)
+	if err == sql.ErrNoRows {
+		return run, fmt.Errorf("run %d not found", id)
+	}
+	return run, err
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRun reads a row of the runs table
+func scanRun(s scanner) (Run, error) {
+	var run Run
+	var endTime sql.NullTime
+	var environment, suiteFile, suiteHash, gitCommit, host sql.NullString
+	var seed sql.NullInt64
+	err := s.Scan(&run.ID, &run.StartTime, &endTime, &environment, &suiteFile, &suiteHash, &gitCommit, &host, &seed,
+		&run.Total, &run.Passed, &run.Failed, &run.Blocked)
+	if err != nil {
+		return run, err
+	}
+	run.EndTime = endTime.Time
+	run.Environment = environment.String
+	run.SuiteFile = suiteFile.String
+	run.SuiteHash = suiteHash.String
+	run.GitCommit = gitCommit.String
+	run.Host = host.String
+	run.Seed = seed.Int64
+	return run, nil
+}
@@ -3,77 +3,114 @@ package db
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"time"
 
 	_ "modernc.org/sqlite" // Pure Go SQLite driver, no cgo required
 )
 
-var DB *sql.DB
-
-// InitDB initializes the SQLite database and creates the table if it does not exist
-func InitDB(path string) {
-	var err error
-	DB, err = sql.Open("sqlite", path)
+// OpenSQLite opens (creating it if needed) the SQLite database stored in the file at path
+func OpenSQLite(path string) (Store, error) {
+	conn, err := sql.Open("sqlite", path)
 	if err != nil {
-		log.Fatalf("Error opening SQLite database: %v", err)
+		return nil, fmt.Errorf("error opening SQLite database: %v", err)
 	}
-
-	createTable := `
-	CREATE TABLE IF NOT EXISTS test_results (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		test_id TEXT,
-		test_case TEXT,
-		result BOOLEAN,
-		message TEXT,
-		run_date DATETIME
-	);
-	`
-	_, err = DB.Exec(createTable)
-	if err != nil {
-		log.Fatalf("Error creating table: %v", err)
+	if err := checkConnection(conn, "SQLite"); err != nil {
+		return nil, err
 	}
+	return &sqlStore{db: conn, dialect: sqliteDialect{}}, nil
 }
 
-// SaveResult saves a test result in the database
-func SaveResult(testId, testCase string, result bool, message string) error {
-	_, err := DB.Exec(`
-		INSERT INTO test_results (test_id, test_case, result, message, run_date)
-		VALUES (?, ?, ?, ?, ?)
-	`, testId, testCase, result, message, time.Now())
-	if err != nil {
-		return fmt.Errorf("error saving result in DB: %v", err)
+// sqliteDialect stores timestamps as text, so they are compared as text too
+type sqliteDialect struct{}
+
+func (sqliteDialect) name() string           { return "sqlite" }
+func (sqliteDialect) placeholder(int) string { return "?" }
+func (sqliteDialect) timestampType() string  { return "DATETIME" }
+func (sqliteDialect) noLimit() interface{}   { return -1 }
+func (sqliteDialect) timeArg(t time.Time) interface{} {
+	// Matches the prefix of the stored values, so dates compare as text
+	return t.Local().Format(dateLayout)
+}
+
+// baseline marks as applied the migrations whose changes already exist in
+// databases created before migrations were introduced, so they are not applied twice
+func (sqliteDialect) baseline(s *sqlStore) error {
+	var count int
+	if err := s.queryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&count); err != nil {
+		return fmt.Errorf("error reading schema version: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	legacy := []struct {
+		version int
+		name    string
+		present func() (bool, error)
+	}{
+		{1, "create_test_results", func() (bool, error) { return sqliteTableExists(s, "test_results") }},
+		{2, "create_runs", func() (bool, error) { return sqliteColumnExists(s, "test_results", "run_id") }},
+	}
+	for _, l := range legacy {
+		present, err := l.present()
+		if err != nil {
+			return err
+		}
+		if !present {
+			break
+		}
+		_, err = s.exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`, l.version, l.name, time.Now())
+		if err != nil {
+			return fmt.Errorf("error recording legacy schema: %v", err)
+		}
 	}
 	return nil
 }
 
-// GetHistory retrieves all historical test results
-func GetHistory() ([]map[string]interface{}, error) {
-	rows, err := DB.Query(`
-		SELECT test_id, test_case, result, message, run_date
-		FROM test_results
-		ORDER BY run_date DESC
-	`)
+// tableExists reports whether the database has the given table
+func (sqliteDialect) tableExists(s *sqlStore, table string) (bool, error) {
+	return sqliteTableExists(s, table)
+}
+
+// size returns the size in bytes of the database, as reported by SQLite
+func (sqliteDialect) size(s *sqlStore) (int64, error) {
+	var pages, pageSize sql.NullInt64
+	if err := s.queryRow("PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, fmt.Errorf("error reading database size: %v", err)
+	}
+	if err := s.queryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("error reading database size: %v", err)
+	}
+	return pages.Int64 * pageSize.Int64, nil
+}
+
+// sqliteTableExists reports whether a table exists in the database
+func sqliteTableExists(s *sqlStore, table string) (bool, error) {
+	var name string
+	err := s.queryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&name)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// sqliteColumnExists reports whether a table has the given column
+func sqliteColumnExists(s *sqlStore, table, column string) (bool, error) {
+	rows, err := s.query(fmt.Sprintf("PRAGMA table_info(%s)", table))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving history from DB: %v", err)
+		return false, err
 	}
 	defer rows.Close()
-
-	var history []map[string]interface{}
 	for rows.Next() {
-		var testId, testCase, message string
-		var result bool
-		var runDate string
-		if err := rows.Scan(&testId, &testCase, &result, &message, &runDate); err != nil {
-			return nil, err
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
 		}
-		history = append(history, map[string]interface{}{
-			"test_id":   testId,
-			"test_case": testCase,
-			"result":    result,
-			"message":   message,
-			"run_date":  runDate,
-		})
 	}
-	return history, nil
+	return false, rows.Err()
 }
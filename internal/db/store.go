@@ -0,0 +1,182 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api-testing/internal/api"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Store persists the history: runs, their results and the exchanges of failed tests.
+// SQLite and PostgreSQL implementations are available, selected by the DSN given to Open.
+type Store interface {
+	// Migrate applies the pending schema migrations and returns the ones it applied
+	Migrate() ([]Migration, error)
+	// Migrations lists every known migration and when it was applied
+	Migrations() ([]Migration, error)
+	// MigrationStatus lists every known migration and when it was applied, without writing to the database
+	MigrationStatus() ([]Migration, error)
+
+	// StartRun records the beginning of a run and returns its id
+	StartRun(run Run) (int64, error)
+	// FinishRun records the end time and totals of a run
+	FinishRun(run Run) error
+	// GetRuns retrieves the most recent runs, newest first (all of them if limit <= 0)
+	GetRuns(limit int) ([]Run, error)
+	// GetRun retrieves a single run by id
+	GetRun(id int64) (Run, error)
+
+	// SaveResult saves a test result of the given run and returns its id
+	SaveResult(runID int64, r Result) (int64, error)
+	// GetResult retrieves a single saved result by id
+	GetResult(id int64) (HistoryEntry, error)
+	// QueryHistory retrieves the saved results that match the filter, newest first by default
+	QueryHistory(f HistoryFilter) ([]HistoryEntry, error)
+
+	// SaveExchange stores the request and response of a saved result
+	SaveExchange(resultID int64, ex api.Exchange) error
+	// GetExchange retrieves the exchange stored for a result, or nil if there is none
+	GetExchange(resultID int64) (*api.Exchange, error)
+
+	// Prune deletes the history that falls outside the retention policy
+	Prune(p RetentionPolicy, now time.Time) (PruneStats, error)
+	// Vacuum gives the space of deleted rows back to the file system
+	Vacuum() error
+	// Size returns the size of the database in bytes
+	Size() (int64, error)
+
+	// Close releases the connection to the database
+	Close() error
+}
+
+// Default is the store opened by InitDB (or OpenDefault) and used by the rest of the application
+var Default Store
+
+// InitDB opens the store selected by the DSN and upgrades its schema to the latest version
+func InitDB(dsn string) {
+	OpenDefault(dsn)
+
+	applied, err := Default.Migrate()
+	if err != nil {
+		log.Fatalf("Error migrating database: %v", err)
+	}
+	for _, m := range applied {
+		log.Printf("Applied database migration %04d_%s", m.Version, m.Name)
+	}
+}
+
+// OpenDefault opens the store selected by the DSN as Default, leaving its schema as it is,
+// for the commands that manage the migrations themselves
+func OpenDefault(dsn string) {
+	var err error
+	Default, err = Open(dsn)
+	if err != nil {
+		log.Fatalf("Error opening database: %v", err)
+	}
+}
+
+// Open connects to the database designated by dsn: a postgres:// or postgresql:// URL
+// selects PostgreSQL, anything else is the path of a SQLite file (optionally prefixed by sqlite://)
+func Open(dsn string) (Store, error) {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		return OpenPostgres(dsn)
+	default:
+		return OpenSQLite(strings.TrimPrefix(dsn, "sqlite://"))
+	}
+}
+
+// GetHistory retrieves all historical test results of the default store, newest first
+func GetHistory() ([]HistoryEntry, error) {
+	return Default.QueryHistory(HistoryFilter{})
+}
+
+// GetRunResults retrieves the results saved for a run in the default store, in execution order
+func GetRunResults(runID int64) ([]HistoryEntry, error) {
+	return Default.QueryHistory(HistoryFilter{RunID: runID, Ascending: true})
+}
+
+// dialect holds what differs between the SQL databases supported by sqlStore
+type dialect interface {
+	// name identifies the dialect and the directory of its migrations
+	name() string
+	// placeholder returns the bind parameter for the n-th argument (1-based) of a query
+	placeholder(n int) string
+	// timestampType is the column type used for timestamps
+	timestampType() string
+	// timeArg converts a time into a value that compares correctly with stored timestamps
+	timeArg(t time.Time) interface{}
+	// noLimit is the LIMIT argument meaning "all rows"
+	noLimit() interface{}
+	// baseline marks as applied the migrations already present in databases older than migrations
+	baseline(s *sqlStore) error
+	// tableExists reports whether the table exists in the database (the current schema for PostgreSQL)
+	tableExists(s *sqlStore, table string) (bool, error)
+	// size returns the size of the database in bytes
+	size(s *sqlStore) (int64, error)
+}
+
+// sqlStore implements Store on a database/sql connection. Queries are written with "?"
+// placeholders and rewritten for the dialect when needed.
+type sqlStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// Close releases the connection to the database
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+// exec runs a statement that returns no rows
+func (s *sqlStore) exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.db.Exec(s.rebind(query), args...)
+}
+
+// query runs a statement that returns rows
+func (s *sqlStore) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.Query(s.rebind(query), args...)
+}
+
+// queryRow runs a statement that returns at most one row
+func (s *sqlStore) queryRow(query string, args ...interface{}) *sql.Row {
+	return s.db.QueryRow(s.rebind(query), args...)
+}
+
+// rebind replaces the "?" placeholders of a query, outside string literals, with the ones of the dialect
+func (s *sqlStore) rebind(query string) string {
+	if s.dialect.placeholder(1) == "?" {
+		return query
+	}
+	var b strings.Builder
+	n, quoted := 0, false
+	for _, c := range query {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted:
+			n++
+			b.WriteString(s.dialect.placeholder(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// numberedPlaceholder returns PostgreSQL-style bind parameters: $1, $2...
+func numberedPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// checkConnection verifies that the database is reachable, closing it otherwise
+func checkConnection(db *sql.DB, name string) error {
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return fmt.Errorf("error connecting to %s database: %v", name, err)
+	}
+	return nil
+}
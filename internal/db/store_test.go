@@ -0,0 +1,304 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api-testing/internal/api"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postgresTestURL returns the PostgreSQL server the store tests also run against, from
+// TEST_POSTGRES_URL only: DATABASE_URL may name a shared history and is never used.
+func postgresTestURL() string {
+	return os.Getenv("TEST_POSTGRES_URL")
+}
+
+// forEachDialect runs fn as a subtest for SQLite and for PostgreSQL, which is skipped
+// unless postgresTestURL names a database.
+func forEachDialect(t *testing.T, fn func(t *testing.T, dialect string)) {
+	for _, dialect := range []string{"sqlite", "postgres"} {
+		t.Run(dialect, func(t *testing.T) { fn(t, dialect) })
+	}
+}
+
+// openEmptyStore opens a store of the dialect without any table: a new SQLite file,
+// or a new schema of the PostgreSQL test database, dropped when the test ends, so the
+// tables already in the database are never touched.
+func openEmptyStore(t *testing.T, dialect string) *sqlStore {
+	t.Helper()
+	var store Store
+	var err error
+	switch dialect {
+	case "sqlite":
+		store, err = OpenSQLite(filepath.Join(t.TempDir(), "history.db"))
+	case "postgres":
+		url := postgresTestURL()
+		if url == "" {
+			t.Skip("set TEST_POSTGRES_URL to run the PostgreSQL tests")
+		}
+		store, err = OpenPostgres(withSearchPath(url, createTestSchema(t, url)))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store.(*sqlStore)
+}
+
+// createTestSchema creates a PostgreSQL schema for a test and drops it, with the
+// tables created in it, when the test ends.
+func createTestSchema(t *testing.T, url string) string {
+	t.Helper()
+	conn, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("apitest_%d_%d", os.Getpid(), time.Now().UnixNano())
+	if _, err := conn.Exec("CREATE SCHEMA " + schema); err != nil {
+		conn.Close()
+		t.Fatalf("creating PostgreSQL test schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := conn.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("dropping PostgreSQL test schema %s: %v", schema, err)
+		}
+		conn.Close()
+	})
+	return schema
+}
+
+// withSearchPath adds a search_path to a PostgreSQL connection string, given as a URL
+// or as "key=value" pairs, so that the tables of the connection live in that schema.
+func withSearchPath(dsn, schema string) string {
+	u, err := neturl.Parse(dsn)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return dsn + " search_path=" + schema
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// openTestStore opens an empty store of the dialect with the latest schema.
+func openTestStore(t *testing.T, dialect string) *sqlStore {
+	t.Helper()
+	s := openEmptyStore(t, dialect)
+	if _, err := s.Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return s
+}
+
+func TestWithSearchPath(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"postgres://u:p@db:5432/test?sslmode=disable", "postgres://u:p@db:5432/test?search_path=s1&sslmode=disable"},
+		{"postgresql://db/test?search_path=public", "postgresql://db/test?search_path=s1"},
+		{"host=db dbname=test sslmode=disable", "host=db dbname=test sslmode=disable search_path=s1"},
+	}
+	for _, tt := range tests {
+		if got := withSearchPath(tt.dsn, "s1"); got != tt.want {
+			t.Errorf("withSearchPath(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"INSERT INTO t VALUES (?, ?, ?)", "INSERT INTO t VALUES ($1, $2, $3)"},
+		{"SELECT * FROM t WHERE a = ? AND b = '?' AND c = ?", "SELECT * FROM t WHERE a = $1 AND b = '?' AND c = $2"},
+		{"SELECT 'it''s ?', ?", "SELECT 'it''s ?', $1"},
+	}
+	sqlite := &sqlStore{dialect: sqliteDialect{}}
+	postgres := &sqlStore{dialect: postgresDialect{}}
+	for _, tt := range tests {
+		if got := sqlite.rebind(tt.query); got != tt.query {
+			t.Errorf("SQLite rebind(%q) = %q, want it unchanged", tt.query, got)
+		}
+		if got := postgres.rebind(tt.query); got != tt.want {
+			t.Errorf("PostgreSQL rebind(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestStoreRuns(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, dialect string) {
+		s := openTestStore(t, dialect)
+		start := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+		var ids []int64
+		for _, env := range []string{"staging", "prod", "staging"} {
+			id, err := s.StartRun(Run{StartTime: start, Environment: env, SuiteFile: "suite.csv", SuiteHash: "abc", GitCommit: "c0ffee", Host: "ci", Seed: 42})
+			if err != nil {
+				t.Fatalf("StartRun() error = %v", err)
+			}
+			if len(ids) > 0 && id <= ids[len(ids)-1] {
+				t.Errorf("StartRun() returned id %d after %d, want increasing ids", id, ids[len(ids)-1])
+			}
+			ids = append(ids, id)
+		}
+
+		end := start.Add(30 * time.Second)
+		if err := s.FinishRun(Run{ID: ids[0], EndTime: end, Total: 3, Passed: 1, Failed: 1, Blocked: 1}); err != nil {
+			t.Fatalf("FinishRun() error = %v", err)
+		}
+		run, err := s.GetRun(ids[0])
+		if err != nil {
+			t.Fatalf("GetRun() error = %v", err)
+		}
+		if run.Environment != "staging" || run.SuiteHash != "abc" || run.GitCommit != "c0ffee" || run.Host != "ci" || run.Seed != 42 ||
+			run.Total != 3 || run.Passed != 1 || run.Failed != 1 || run.Blocked != 1 || run.Duration() != 30*time.Second {
+			t.Errorf("GetRun() = %+v", run)
+		}
+		if unfinished, _ := s.GetRun(ids[1]); !unfinished.EndTime.IsZero() || unfinished.Duration() != 0 {
+			t.Errorf("unfinished run = %+v, want no end time", unfinished)
+		}
+		if _, err := s.GetRun(ids[2] + 100); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("GetRun(unknown) error = %v, want not found", err)
+		}
+
+		runs, err := s.GetRuns(2)
+		if err != nil {
+			t.Fatalf("GetRuns() error = %v", err)
+		}
+		if len(runs) != 2 || runs[0].ID != ids[2] || runs[1].ID != ids[1] {
+			t.Errorf("GetRuns(2) = %+v, want the last two runs, newest first", runs)
+		}
+		if all, _ := s.GetRuns(0); len(all) != 3 {
+			t.Errorf("GetRuns(0) returned %d runs, want 3", len(all))
+		}
+	})
+}
+
+func TestStoreResults(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, dialect string) {
+		s := openTestStore(t, dialect)
+		before := time.Now().Add(-time.Second)
+
+		runs := make([]int64, 2)
+		for i, env := range []string{"staging", "prod"} {
+			var err error
+			if runs[i], err = s.StartRun(Run{StartTime: time.Now(), Environment: env}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		saved := []struct {
+			run int64
+			r   Result
+		}{
+			{runs[0], Result{TestID: "A", TestCase: "List users", Passed: true, Status: "passed", Message: "ok", Duration: 120 * time.Millisecond, Method: "GET", Endpoint: "/users"}},
+			{runs[0], Result{TestID: "B", TestCase: "Create user", Status: "failed", Message: "Expected 201, got 500", Duration: time.Second, Method: "POST", Endpoint: "/users"}},
+			{runs[1], Result{TestID: "A", TestCase: "List users", Passed: true, Status: "passed", Message: "ok", Method: "GET", Endpoint: "/users"}},
+			{runs[1], Result{TestID: "C", TestCase: "Delete user", Status: "blocked", Message: "Blocked by B (failed)"}},
+		}
+		var ids []int64
+		for _, r := range saved {
+			id, err := s.SaveResult(r.run, r.r)
+			if err != nil {
+				t.Fatalf("SaveResult() error = %v", err)
+			}
+			if len(ids) > 0 && id <= ids[len(ids)-1] {
+				t.Errorf("SaveResult() returned id %d after %d, want increasing ids", id, ids[len(ids)-1])
+			}
+			ids = append(ids, id)
+		}
+
+		got, err := s.GetResult(ids[1])
+		if err != nil {
+			t.Fatalf("GetResult() error = %v", err)
+		}
+		want := HistoryEntry{ID: ids[1], RunID: runs[0], TestID: "B", TestCase: "Create user", Status: "failed", Message: "Expected 201, got 500",
+			DurationMs: 1000, RunDate: got.RunDate, Environment: "staging", Method: "POST", Endpoint: "/users"}
+		if got != want {
+			t.Errorf("GetResult() = %+v, want %+v", got, want)
+		}
+		if got.RunDate.Before(before) || got.RunDate.After(time.Now()) {
+			t.Errorf("GetResult() run date = %v, want the time it was saved", got.RunDate)
+		}
+		if _, err := s.GetResult(ids[3] + 100); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("GetResult(unknown) error = %v, want not found", err)
+		}
+
+		tests := []struct {
+			name   string
+			filter HistoryFilter
+			want   []int64
+		}{
+			{"all", HistoryFilter{}, []int64{ids[3], ids[2], ids[1], ids[0]}},
+			{"ascending", HistoryFilter{Ascending: true}, ids},
+			{"test id", HistoryFilter{TestID: "A"}, []int64{ids[2], ids[0]}},
+			{"status", HistoryFilter{Status: "blocked"}, []int64{ids[3]}},
+			{"run", HistoryFilter{RunID: runs[0], Ascending: true}, []int64{ids[0], ids[1]}},
+			{"environment", HistoryFilter{Environment: "prod"}, []int64{ids[3], ids[2]}},
+			{"last runs", HistoryFilter{LastRuns: 1}, []int64{ids[3], ids[2]}},
+			{"limit", HistoryFilter{Limit: 2, Offset: 1}, []int64{ids[2], ids[1]}},
+			{"offset only", HistoryFilter{Offset: 3}, []int64{ids[0]}},
+			{"from", HistoryFilter{From: before}, []int64{ids[3], ids[2], ids[1], ids[0]}},
+			{"to", HistoryFilter{To: before}, nil},
+		}
+		for _, tt := range tests {
+			history, err := s.QueryHistory(tt.filter)
+			if err != nil {
+				t.Fatalf("QueryHistory(%s) error = %v", tt.name, err)
+			}
+			var got []int64
+			for _, e := range history {
+				got = append(got, e.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryHistory(%s) = %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	})
+}
+
+func TestStoreExchanges(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, dialect string) {
+		s := openTestStore(t, dialect)
+		runID, err := s.StartRun(Run{StartTime: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resultID, err := s.SaveResult(runID, Result{TestID: "A", Status: "failed"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exchange := api.Exchange{
+			Request:   api.Request{Method: "POST", URL: "https://api.test/users", Headers: http.Header{"Content-Type": {"application/json"}}, Body: `{"name":"Ann"}`},
+			Response:  api.Response{StatusCode: 500, Headers: http.Header{"X-Trace": {"a", "b"}}, Body: "boom"},
+			Truncated: true,
+		}
+		if err := s.SaveExchange(resultID, exchange); err != nil {
+			t.Fatalf("SaveExchange() error = %v", err)
+		}
+		got, err := s.GetExchange(resultID)
+		if err != nil || got == nil {
+			t.Fatalf("GetExchange() = %v, %v", got, err)
+		}
+		if !reflect.DeepEqual(got.Request, exchange.Request) || !reflect.DeepEqual(got.Response, exchange.Response) || !got.Truncated {
+			t.Errorf("GetExchange() = %+v, want %+v", *got, exchange)
+		}
+		if missing, err := s.GetExchange(resultID + 100); missing != nil || err != nil {
+			t.Errorf("GetExchange(unknown) = %v, %v; want nil, nil", missing, err)
+		}
+
+		if size, err := s.Size(); err != nil || size <= 0 {
+			t.Errorf("Size() = %d, %v; want a positive size", size, err)
+		}
+	})
+}
@@ -0,0 +1,446 @@
+package importer
+
+import (
+	"fmt"
+	"go-api-testing/models"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// curlFlags lists the curl options without an argument that are ignored, because they
+// only change how curl reports or follows the exchange (the test runner decompresses
+// and follows redirects on its own).
+var curlFlags = map[string]bool{
+	"-s": true, "--silent": true, "-S": true, "--show-error": true, "-v": true, "--verbose": true,
+	"-i": true, "--include": true, "-L": true, "--location": true, "-k": true, "--insecure": true,
+	"--compressed": true, "-f": true, "--fail": true, "--fail-with-body": true, "-g": true, "--globoff": true,
+	"-#": true, "--progress-bar": true, "-N": true, "--no-buffer": true, "--http1.1": true, "--http2": true,
+	"-4": true, "--ipv4": true, "-6": true, "--ipv6": true, "-n": true, "--netrc": true,
+}
+
+// curlIgnoredOptions lists the curl options with an argument that have no equivalent
+// in a test case; they are skipped with a warning.
+var curlIgnoredOptions = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true, "--connect-timeout": true,
+	"--retry": true, "-x": true, "--proxy": true, "--cacert": true, "-E": true, "--cert": true,
+	"--key": true, "-w": true, "--write-out": true, "-c": true, "--cookie-jar": true,
+	"--resolve": true, "--connect-to": true, "-r": true, "--range": true, "-T": true, "--upload-file": true,
+}
+
+// curlShortWithArgument lists the short options that take an argument, which may be
+// attached to them (e.g. "-XPOST").
+const curlShortWithArgument = "XHdubAeFomxrEwcT"
+
+// curlLongWithArgument lists the translated long options that take an argument.
+var curlLongWithArgument = map[string]bool{
+	"--request": true, "--url": true, "--header": true, "--data": true, "--data-ascii": true,
+	"--data-binary": true, "--data-raw": true, "--data-urlencode": true, "--json": true, "--user": true,
+	"--oauth2-bearer": true, "--user-agent": true, "--referer": true, "--cookie": true,
+	"--form": true, "--form-string": true,
+}
+
+// curlCommand accumulates the request described by the options of a curl command.
+type curlCommand struct {
+	method      string
+	rawURL      string
+	headers     []header
+	data        []string
+	contentType string // Implicit Content-Type of the data options.
+	get         bool   // -G: send the data in the query string.
+	user        string
+	bearer      string
+	warnings    []string
+}
+
+// ImportCurl converts curl command lines, as pasted in tickets or copied from the
+// browser developer tools ("Copy as cURL"), into test cases. The input may hold several
+// commands, one per line (with "\" continuations); other commands, such as the "jq" of
+// a pipe, are ignored. The method, URL (from --url or the first argument), -H headers,
+// -d/--data/--data-raw/--data-binary/--data-urlencode/--json bodies (sent in the query
+// string with -G) and -u credentials are translated. A curl command does not record
+// the response, so the expected status is 200.
+//
+// Parameters:
+//   - commands (io.Reader): The command lines, in bash syntax.
+//   - opts (Options): The conversion options.
+//
+// Returns:
+//   - []models.TestCase: The test cases, one per curl command.
+//   - []Warning: Options that were ignored, such as output files, forms or data read from files.
+//   - error: An error if the input holds no curl command or cannot be parsed.
+func ImportCurl(commands io.Reader, opts Options) ([]models.TestCase, []Warning, error) {
+	content, err := io.ReadAll(commands)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading curl commands: %v", err)
+	}
+	lines, err := shellCommands(string(content))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing curl commands: %v", err)
+	}
+
+	var testCases []models.TestCase
+	var warnings []Warning
+	for _, words := range lines {
+		if len(words) == 0 || strings.TrimSuffix(path.Base(words[0]), ".exe") != "curl" {
+			continue
+		}
+		c := parseCurl(words[1:])
+		if c.rawURL == "" {
+			warnings = append(warnings, Warning{Item: strings.Join(words, " "), Message: "no URL found; the command is skipped"})
+			continue
+		}
+		tc := c.testCase()
+		tc.TestId = testID(opts.Prefix, len(testCases)+1)
+		for _, message := range c.warnings {
+			warnings = append(warnings, Warning{Item: tc.TestId + " " + tc.TestCase, Message: message})
+		}
+		testCases = append(testCases, tc)
+	}
+	if len(testCases) == 0 {
+		return nil, nil, fmt.Errorf("error parsing curl commands: no curl command found")
+	}
+	warnings = append(warnings, Warning{Message: "curl commands do not record the response, the test cases expect 200"})
+	return testCases, warnings, nil
+}
+
+// parseCurl reads the options of a curl command (without the leading "curl").
+func parseCurl(args []string) *curlCommand {
+	c := &curlCommand{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := arg, "", false
+
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			if n, v, ok := strings.Cut(arg, "="); ok && !curlFlags[arg] {
+				name, value, hasValue = n, v, true
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 2:
+			if strings.ContainsRune(curlShortWithArgument, rune(arg[1])) {
+				name, value, hasValue = arg[:2], arg[2:], true // Attached argument, e.g. -XPOST
+			} else {
+				// Combined flags, e.g. -sSL or -sX POST
+				for j := 1; j < len(arg); j++ {
+					flag := "-" + string(arg[j])
+					if strings.ContainsRune(curlShortWithArgument, rune(arg[j])) {
+						name, value, hasValue = flag, arg[j+1:], j+1 < len(arg)
+						break
+					}
+					c.option(flag, "")
+					name = ""
+				}
+				if name == "" {
+					continue
+				}
+			}
+		case !strings.HasPrefix(arg, "-"):
+			if c.rawURL == "" {
+				c.rawURL = arg
+			} else {
+				c.warnings = append(c.warnings, fmt.Sprintf("only the first URL is imported, %s is ignored", arg))
+			}
+			continue
+		}
+
+		if c.takesArgument(name) && !hasValue {
+			if i+1 >= len(args) {
+				c.warnings = append(c.warnings, fmt.Sprintf("option %s has no value", name))
+				break
+			}
+			i++
+			value = args[i]
+		}
+		c.option(name, value)
+	}
+	return c
+}
+
+// takesArgument reports whether a curl option is followed by a value. Unknown long
+// options are assumed to be flags.
+func (c *curlCommand) takesArgument(name string) bool {
+	if curlIgnoredOptions[name] || curlLongWithArgument[name] {
+		return true
+	}
+	return len(name) == 2 && strings.Contains(curlShortWithArgument, name[1:])
+}
+
+// option applies a curl option to the request.
+func (c *curlCommand) option(name, value string) {
+	switch name {
+	case "-X", "--request":
+		c.method = strings.ToUpper(value)
+	case "--url":
+		c.rawURL = value
+	case "-H", "--header":
+		headerName, headerValue, ok := strings.Cut(value, ":")
+		if !ok {
+			// "Name;" sends an empty header, "Name" alone removes an internal one
+			if n, found := strings.CutSuffix(value, ";"); found {
+				c.headers = append(c.headers, header{strings.TrimSpace(n), ""})
+			}
+			return
+		}
+		c.headers = append(c.headers, header{strings.TrimSpace(headerName), strings.TrimSpace(headerValue)})
+	case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw":
+		if strings.HasPrefix(value, "@") && name != "--data-raw" {
+			c.warnings = append(c.warnings, fmt.Sprintf("data read from the file %s is not imported; paste it in the Body column", value[1:]))
+			return
+		}
+		if name == "-d" || name == "--data" || name == "--data-ascii" {
+			value = strings.NewReplacer("\r", "", "\n", "").Replace(value) // curl strips newlines from these
+		}
+		c.data = append(c.data, value)
+		c.contentType = "application/x-www-form-urlencoded"
+	case "--data-urlencode":
+		c.data = append(c.data, urlencodeData(value))
+		c.contentType = "application/x-www-form-urlencoded"
+	case "--json":
+		c.data = append(c.data, value)
+		c.contentType = "application/json"
+		c.headers = append(c.headers, header{"Accept", "application/json"})
+	case "-u", "--user":
+		c.user = value
+	case "--oauth2-bearer":
+		c.bearer = value
+	case "-A", "--user-agent":
+		c.headers = append(c.headers, header{"User-Agent", value})
+	case "-e", "--referer":
+		c.headers = append(c.headers, header{"Referer", value})
+	case "-b", "--cookie":
+		if strings.Contains(value, "=") {
+			c.headers = append(c.headers, header{"Cookie", value})
+		} else {
+			c.warnings = append(c.warnings, fmt.Sprintf("cookies read from the file %s are not imported", value))
+		}
+	case "-G", "--get":
+		c.get = true
+	case "-I", "--head":
+		c.method = "HEAD"
+	case "-F", "--form", "--form-string":
+		c.warnings = append(c.warnings, "multipart forms (-F) are not supported; the request is imported without them")
+	default:
+		if curlIgnoredOptions[name] {
+			c.warnings = append(c.warnings, fmt.Sprintf("option %s is ignored", name))
+		} else if !curlFlags[name] {
+			c.warnings = append(c.warnings, fmt.Sprintf("unknown option %s is ignored", name))
+		}
+	}
+}
+
+// testCase builds the test case of the command, the way curl would send the request.
+func (c *curlCommand) testCase() models.TestCase {
+	rawURL := c.rawURL
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL // curl assumes http when the protocol is omitted
+	}
+	data := strings.Join(c.data, "&")
+
+	tc := models.TestCase{Run: "Y", ExpectedStatusCode: 200, Method: c.method}
+	if c.get && data != "" {
+		separator := "?"
+		if strings.Contains(rawURL, "?") {
+			separator = "&"
+		}
+		rawURL, data = rawURL+separator+data, ""
+	}
+	if tc.Method == "" {
+		tc.Method = "GET"
+		if data != "" {
+			tc.Method = "POST"
+		}
+	}
+	tc.Body = data
+
+	headers := skipTransportHeaders(c.headers)
+	if tc.Body != "" && !hasHeader(headers, "Content-Type") {
+		headers = append(headers, header{"Content-Type", c.contentType})
+	}
+	var repeated []string
+	tc.Headers, repeated = headersJSON(headers)
+	if len(repeated) > 0 {
+		c.warnings = append(c.warnings, fmt.Sprintf("only the last value of the repeated headers is kept: %s", strings.Join(repeated, ", ")))
+	}
+
+	switch {
+	case c.bearer != "":
+		tc.Authorization, tc.User = "Bearer", c.bearer
+	case c.user != "":
+		tc.Authorization = "Basic"
+		tc.User, tc.Password, _ = strings.Cut(c.user, ":")
+		if tc.Password == "" {
+			c.warnings = append(c.warnings, "the password of -u was not given (curl would prompt for it); set it in the Password column")
+		}
+	}
+
+	// Credentials embedded in the URL become basic authentication
+	if u, err := url.Parse(rawURL); err == nil && u.User != nil {
+		password, _ := u.User.Password()
+		tc.Authorization, tc.User, tc.Password = "Basic", u.User.Username(), password
+		u.User = nil
+		rawURL = u.String()
+	}
+
+	tc.URL, tc.Endpoint = splitURL(rawURL)
+	endpoint, _, _ := strings.Cut(tc.Endpoint, "?")
+	if endpoint == "" {
+		endpoint = "/"
+	}
+	tc.TestCase = tc.Method + " " + endpoint
+	return tc
+}
+
+// urlencodeData encodes a --data-urlencode value the way curl does: "name=value" and
+// "=value" encode the value, a value without "=" is encoded as a whole.
+func urlencodeData(value string) string {
+	if name, content, ok := strings.Cut(value, "="); ok {
+		if name == "" {
+			return url.QueryEscape(content)
+		}
+		return name + "=" + url.QueryEscape(content)
+	}
+	return url.QueryEscape(value)
+}
+
+// shellCommands splits a shell script into commands and each command into words,
+// honouring single and double quotes, ANSI-C quotes ($'...'), backslash escapes,
+// line continuations and comments. Newlines, ";", "|" and "&" end a command.
+func shellCommands(script string) ([][]string, error) {
+	var commands [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
+	}
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+		switch {
+		case ch == '\\':
+			if i+1 < len(script) && script[i+1] == '\n' {
+				i++ // Line continuation
+			} else if i+2 < len(script) && script[i+1] == '\r' && script[i+2] == '\n' {
+				i += 2
+			} else if i+1 < len(script) {
+				word.WriteByte(script[i+1])
+				inWord = true
+				i++
+			}
+		case ch == '\'':
+			end := strings.IndexByte(script[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(script[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case ch == '"':
+			i++
+			for ; i < len(script) && script[i] != '"'; i++ {
+				if script[i] == '\\' && i+1 < len(script) && strings.IndexByte("$`\"\\\n", script[i+1]) >= 0 {
+					i++
+					if script[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(script[i])
+			}
+			if i >= len(script) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inWord = true
+		case ch == '$' && i+1 < len(script) && script[i+1] == '\'':
+			n, err := ansiCQuoted(script[i+2:], &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i += n + 1
+		case ch == '#' && !inWord:
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			endCommand()
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			endWord()
+		case ch == '\n' || ch == ';' || ch == '|' || ch == '&':
+			endCommand()
+		default:
+			word.WriteByte(ch)
+			inWord = true
+		}
+	}
+	endCommand()
+	return commands, nil
+}
+
+// ansiCQuoted decodes the content of a $'...' string, starting after the opening quote,
+// into word. It returns the number of bytes read, closing quote included.
+func ansiCQuoted(s string, word *strings.Builder) (int, error) {
+	escapes := map[byte]string{'n': "\n", 't': "\t", 'r': "\r", '\\': "\\", '\'': "'", '"': "\"", 'a': "\a", 'b': "\b", 'e': "\x1b", 'f': "\f", 'v': "\v", '?': "?"}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' {
+			return i + 1, nil
+		}
+		if s[i] != '\\' || i+1 == len(s) {
+			word.WriteByte(s[i])
+			continue
+		}
+		i++
+		c := s[i]
+		if text, ok := escapes[c]; ok {
+			word.WriteString(text)
+			continue
+		}
+
+		// Numeric escapes: \xHH, \uHHHH, \UHHHHHHHH and octal \NNN
+		base, size, start := 16, 0, i+1
+		switch {
+		case c == 'x':
+			size = 2
+		case c == 'u':
+			size = 4
+		case c == 'U':
+			size = 8
+		case c >= '0' && c <= '7':
+			base, size, start = 8, 3, i
+		}
+		end := start
+		for end < len(s) && end-start < size && isDigit(s[end], base) {
+			end++
+		}
+		if end == start {
+			word.WriteByte('\\')
+			word.WriteByte(c)
+			continue
+		}
+		code, _ := strconv.ParseUint(s[start:end], base, 32)
+		if c == 'u' || c == 'U' {
+			word.WriteRune(rune(code))
+		} else {
+			word.WriteByte(byte(code))
+		}
+		i = end - 1
+	}
+	return 0, fmt.Errorf("unterminated $'...' quote")
+}
+
+// isDigit reports whether c is a digit in the given base.
+func isDigit(c byte, base int) bool {
+	_, err := strconv.ParseUint(string(c), base, 8)
+	return err == nil
+}
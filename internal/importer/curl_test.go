@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"go-api-testing/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportCurl(t *testing.T) {
+	cases, warnings, err := ImportCurl(openFixture(t, "curl_commands.sh"), Options{})
+	if err != nil {
+		t.Fatalf("ImportCurl() error = %v", err)
+	}
+	checkTestCases(t, cases, []models.TestCase{
+		{TestId: "TC-001", TestCase: "GET /v1/users", Run: "Y", Method: "GET", URL: "https://api.example.com", Endpoint: "/v1/users?page=1",
+			Headers: `{"Accept":"application/json","Authorization":"Bearer $TOKEN"}`, ExpectedStatusCode: 200},
+		{TestId: "TC-002", TestCase: "POST /v1/users", Run: "Y", Method: "POST", URL: "https://api.example.com", Endpoint: "/v1/users",
+			Authorization: "Basic", User: "ann", Password: "secret", Headers: `{"Accept":"application/json","Content-Type":"application/json"}`,
+			Body: `{"name": "Ann O'Neil"}`, ExpectedStatusCode: 200},
+		{TestId: "TC-003", TestCase: "GET /search", Run: "Y", Method: "GET", URL: "http://api.example.com", Endpoint: "/search?q=a+b%26c&limit=5", ExpectedStatusCode: 200},
+		{TestId: "TC-004", TestCase: "PUT /v1/avatar", Run: "Y", Method: "PUT", URL: "https://api.example.com", Endpoint: "/v1/avatar",
+			Authorization: "Basic", User: "bob", Password: "pw", Headers: `{"Cookie":"session=abc"}`, ExpectedStatusCode: 200},
+		{TestId: "TC-005", TestCase: "POST /v1/notes", Run: "Y", Method: "POST", URL: "https://api.example.com", Endpoint: "/v1/notes",
+			Authorization: "Bearer", User: "tok", Headers: `{"Content-Type":"application/x-www-form-urlencoded","X-Id":"2"}`, Body: "line1line2", ExpectedStatusCode: 200},
+	})
+
+	wantWarnings := []string{
+		"TC-003 GET /search: option -o is ignored",
+		"TC-004 PUT /v1/avatar: multipart forms (-F) are not supported; the request is imported without them",
+		"TC-004 PUT /v1/avatar: data read from the file body.json is not imported; paste it in the Body column",
+		"TC-005 POST /v1/notes: only the last value of the repeated headers is kept: X-Id",
+		"curl -s --verbose: no URL found; the command is skipped",
+		"curl commands do not record the response, the test cases expect 200",
+	}
+	if got := warningStrings(warnings); !reflect.DeepEqual(got, wantWarnings) {
+		t.Errorf("warnings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantWarnings, "\n"))
+	}
+}
+
+func TestImportCurlErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands string
+		wantErr  string
+	}{
+		{"no curl command", "wget https://api.example.com\necho done", "no curl command found"},
+		{"unterminated quote", "curl 'https://api.example.com", "unterminated single quote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ImportCurl(strings.NewReader(tt.commands), Options{}); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ImportCurl() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShellCommands(t *testing.T) {
+	tests := []struct {
+		script string
+		want   [][]string
+	}{
+		{`curl -H "X: \"a\" \$b" 'c d'`, [][]string{{"curl", "-H", `X: "a" $b`, "c d"}}},
+		{"curl a \\\n  -v # comment\nls; pwd", [][]string{{"curl", "a", "-v"}, {"ls"}, {"pwd"}}},
+		{`echo $'tab\there\x41é'`, [][]string{{"echo", "tab\thereAé"}}},
+		{"curl a | jq . && echo ok", [][]string{{"curl", "a"}, {"jq", "."}, {"echo", "ok"}}},
+		{`curl a""b\ c`, [][]string{{"curl", "ab c"}}},
+	}
+	for _, tt := range tests {
+		got, err := shellCommands(tt.script)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("shellCommands(%q) = %q, %v, want %q", tt.script, got, err, tt.want)
+		}
+	}
+}
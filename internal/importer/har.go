@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api-testing/models"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// harFile is the subset of the HTTP Archive format 1.2 that is translated.
+type harFile struct {
+	Log struct {
+		Version string     `json:"version"`
+		Pages   []harPage  `json:"pages"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harPage struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type harEntry struct {
+	PageRef      string `json:"pageref"`
+	ResourceType string `json:"_resourceType"` // Added by Chromium-based browsers.
+	Request      struct {
+		Method   string    `json:"method"`
+		URL      string    `json:"url"`
+		Headers  []harPair `json:"headers"`
+		PostData *struct {
+			MimeType string    `json:"mimeType"`
+			Text     string    `json:"text"`
+			Params   []harPair `json:"params"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status  int `json:"status"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+type harPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harBrowserTypes lists the resource types of the requests made by the browser on its
+// own, such as page assets and CORS preflights, which are not API calls.
+var harBrowserTypes = map[string]bool{
+	"document": true, "stylesheet": true, "script": true, "image": true, "font": true, "media": true,
+	"manifest": true, "texttrack": true, "ping": true, "preflight": true,
+}
+
+// ImportHAR converts the entries of an HTTP Archive (HAR 1.2), as exported by the
+// browser developer tools or a proxy, into test cases, in the order they were
+// recorded. Each request becomes a test case with its method, URL, headers and body,
+// expecting the status code recorded in its response, and tagged with the title of
+// its page. Page assets (documents, scripts, stylesheets, images, fonts...), CORS
+// preflights, requests that got no response and the headers the HTTP client sets on
+// its own are left out.
+//
+// Parameters:
+//   - archive (io.Reader): The HAR file.
+//   - opts (Options): The conversion options.
+//
+// Returns:
+//   - []models.TestCase: The test cases.
+//   - []Warning: The entries left out and what could not be translated.
+//   - error: An error if the file is not a valid HAR file.
+func ImportHAR(archive io.Reader, opts Options) ([]models.TestCase, []Warning, error) {
+	var har harFile
+	if err := json.NewDecoder(archive).Decode(&har); err != nil {
+		return nil, nil, fmt.Errorf("error parsing HAR file: %v", err)
+	}
+	if har.Log.Version == "" && har.Log.Entries == nil {
+		return nil, nil, fmt.Errorf("error parsing HAR file: no \"log\" with entries found")
+	}
+
+	pages := make(map[string]string, len(har.Log.Pages))
+	for _, page := range har.Log.Pages {
+		pages[page.ID] = page.Title
+	}
+
+	var testCases []models.TestCase
+	var warnings []Warning
+	assets, unanswered := 0, 0
+	for _, entry := range har.Log.Entries {
+		if isAsset(entry) {
+			assets++
+			continue
+		}
+		if entry.Response.Status <= 0 {
+			unanswered++
+			continue
+		}
+
+		req := entry.Request
+		tc := models.TestCase{
+			TestId:             testID(opts.Prefix, len(testCases)+1),
+			Run:                "Y",
+			Method:             strings.ToUpper(req.Method),
+			ExpectedStatusCode: entry.Response.Status,
+			Tags:               tagList([]string{pages[entry.PageRef]}),
+		}
+		tc.URL, tc.Endpoint = splitURL(req.URL)
+		endpoint, _, _ := strings.Cut(tc.Endpoint, "?")
+		if endpoint == "" {
+			endpoint = "/"
+		}
+		tc.TestCase = tc.Method + " " + endpoint
+		item := tc.TestId + " " + tc.TestCase
+
+		headers := make([]header, 0, len(req.Headers))
+		for _, h := range req.Headers {
+			headers = append(headers, header{h.Name, h.Value})
+		}
+		headers = skipTransportHeaders(headers)
+
+		if post := req.PostData; post != nil {
+			tc.Body = post.Text
+			if tc.Body == "" && len(post.Params) > 0 {
+				if strings.HasPrefix(post.MimeType, "multipart/") {
+					warnings = append(warnings, Warning{Item: item, Message: "multipart form parameters without the raw body are not supported"})
+				} else {
+					// Keep the recorded order: url.Values.Encode would sort the fields
+					form := make([]string, len(post.Params))
+					for i, p := range post.Params {
+						form[i] = url.QueryEscape(p.Name) + "=" + url.QueryEscape(p.Value)
+					}
+					tc.Body = strings.Join(form, "&")
+				}
+			}
+			if tc.Body != "" && post.MimeType != "" && !hasHeader(headers, "Content-Type") {
+				headers = append(headers, header{"Content-Type", post.MimeType})
+			}
+		}
+
+		var repeated []string
+		tc.Headers, repeated = headersJSON(headers)
+		if len(repeated) > 0 {
+			warnings = append(warnings, Warning{Item: item, Message: fmt.Sprintf("only the last value of the repeated headers is kept: %s", strings.Join(repeated, ", "))})
+		}
+		testCases = append(testCases, tc)
+	}
+
+	if assets > 0 {
+		warnings = append(warnings, Warning{Message: fmt.Sprintf("%d page asset or CORS preflight request(s) were skipped", assets)})
+	}
+	if unanswered > 0 {
+		warnings = append(warnings, Warning{Message: fmt.Sprintf("%d request(s) without a response (blocked or cancelled) were skipped", unanswered)})
+	}
+	if len(testCases) == 0 {
+		return nil, nil, fmt.Errorf("error parsing HAR file: no API request found among %d entries", len(har.Log.Entries))
+	}
+	return testCases, warnings, nil
+}
+
+// isAsset reports whether an entry loaded a page asset (or was a CORS preflight) rather
+// than calling an API, using the resource type recorded by the browser, or else the
+// type of the response.
+func isAsset(entry harEntry) bool {
+	if entry.ResourceType != "" {
+		return harBrowserTypes[strings.ToLower(entry.ResourceType)]
+	}
+	mimeType := strings.ToLower(entry.Response.Content.MimeType)
+	for _, prefix := range []string{"text/html", "text/css", "image/", "font/", "audio/", "video/", "application/javascript", "text/javascript", "application/font", "application/wasm"} {
+		if strings.HasPrefix(mimeType, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package importer
+
+import (
+	"go-api-testing/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportHAR(t *testing.T) {
+	cases, warnings, err := ImportHAR(openFixture(t, "session.har"), Options{Prefix: "HAR"})
+	if err != nil {
+		t.Fatalf("ImportHAR() error = %v", err)
+	}
+	checkTestCases(t, cases, []models.TestCase{
+		{TestId: "HAR-001", TestCase: "GET /v1/users", Run: "Y", Method: "GET", URL: "https://api.example.com", Endpoint: "/v1/users?page=1",
+			Headers: `{"Accept":"application/json","Authorization":"Bearer abc"}`, ExpectedStatusCode: 200, Tags: "Users"},
+		{TestId: "HAR-002", TestCase: "POST /v1/users", Run: "Y", Method: "POST", URL: "https://api.example.com", Endpoint: "/v1/users",
+			Headers: `{"Content-Type":"application/json","X-Id":"2"}`, Body: `{"name":"Ann"}`, ExpectedStatusCode: 201, Tags: "Users"},
+		{TestId: "HAR-003", TestCase: "POST /login", Run: "Y", Method: "POST", URL: "https://api.example.com", Endpoint: "/login",
+			Headers: `{"Content-Type":"application/x-www-form-urlencoded"}`, Body: "user=ann&pass=a%26b", ExpectedStatusCode: 302},
+		{TestId: "HAR-004", TestCase: "POST /upload", Run: "Y", Method: "POST", URL: "https://api.example.com", Endpoint: "/upload", ExpectedStatusCode: 200},
+	})
+
+	wantWarnings := []string{
+		"HAR-002 POST /v1/users: only the last value of the repeated headers is kept: X-Id",
+		"HAR-004 POST /upload: multipart form parameters without the raw body are not supported",
+		"3 page asset or CORS preflight request(s) were skipped",
+		"1 request(s) without a response (blocked or cancelled) were skipped",
+	}
+	if got := warningStrings(warnings); !reflect.DeepEqual(got, wantWarnings) {
+		t.Errorf("warnings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantWarnings, "\n"))
+	}
+}
+
+func TestImportHARErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive string
+		wantErr string
+	}{
+		{"invalid JSON", `{"log":`, "error parsing HAR file"},
+		{"no log", `{}`, `no "log" with entries found`},
+		{"only assets", `{"log": {"version": "1.2", "entries": [{"request": {"method": "GET", "url": "https://a.test/x.css"}, "response": {"status": 200, "content": {"mimeType": "text/css"}}}]}}`,
+			"no API request found among 1 entries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ImportHAR(strings.NewReader(tt.archive), Options{}); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ImportHAR() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package importer converts API collections and specifications maintained with other
+// tools into test cases, reporting whatever could not be translated.
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Warning describes something an importer could not translate, or translated only partially.
+type Warning struct {
+	Item    string // Request or operation concerned, empty if it applies to the whole file.
+	Message string // What was not translated and why.
+}
+
+// String returns the warning as "item: message".
+func (w Warning) String() string {
+	if w.Item == "" {
+		return w.Message
+	}
+	return w.Item + ": " + w.Message
+}
+
+// Options tunes the conversion, whatever the source format.
+type Options struct {
+	Prefix  string // Prefix of the generated TestIds, e.g. "PM" produces PM-001, PM-002...
+	BaseURL string // Base URL of the API, replacing the servers declared by an OpenAPI document.
+}
+
+// testID returns the TestId of the n-th imported test case (starting at 1).
+func testID(prefix string, n int) string {
+	if prefix == "" {
+		prefix = "TC"
+	}
+	return fmt.Sprintf("%s-%03d", prefix, n)
+}
+
+// splitURL splits an absolute URL into the base (scheme and host) and the endpoint
+// (path and query), the way the URL and Endpoint columns expect them. Template
+// expressions are kept, so "{{baseUrl}}/users?page=1" gives "{{baseUrl}}" and "/users?page=1".
+func splitURL(raw string) (base, endpoint string) {
+	hostStart := 0
+	if i := strings.Index(raw, "://"); i >= 0 {
+		hostStart = i + 3
+	}
+	end := strings.IndexAny(raw[hostStart:], "/?#")
+	if end < 0 {
+		return raw, ""
+	}
+	base, endpoint = raw[:hostStart+end], raw[hostStart+end:]
+	if i := strings.IndexByte(endpoint, '#'); i >= 0 {
+		endpoint = endpoint[:i] // Fragments are never sent to the server
+	}
+	if strings.HasPrefix(endpoint, "?") {
+		endpoint = "/" + endpoint
+	}
+	return base, endpoint
+}
+
+// header is an HTTP header of an imported request, in declaration order.
+type header struct {
+	name, value string
+}
+
+// headersJSON encodes headers as the JSON object of the Headers column. The column
+// holds a single value per header, so only the last of repeated headers is kept and
+// the names of the others are returned. Header names are case-insensitive: a repeated
+// header keeps the spelling of its first occurrence.
+func headersJSON(headers []header) (string, []string) {
+	if len(headers) == 0 {
+		return "", nil
+	}
+	values := make(map[string]string, len(headers))
+	names := make(map[string]string, len(headers)) // Spelling used, by lower case name.
+	var repeated []string
+	for _, h := range headers {
+		name, ok := names[strings.ToLower(h.name)]
+		if ok {
+			repeated = append(repeated, name)
+		} else {
+			name = h.name
+			names[strings.ToLower(name)] = name
+		}
+		values[name] = h.value
+	}
+	b, _ := json.Marshal(values) // Keys are sorted, so the output is stable
+	return string(b), repeated
+}
+
+// transportHeaders lists the (lower case) headers that the HTTP client sets on its own.
+// Recorded requests carry them, but sending them again would clash with the client:
+// a manual Accept-Encoding, for instance, disables the automatic decompression.
+var transportHeaders = map[string]bool{
+	"host": true, "content-length": true, "connection": true, "keep-alive": true, "accept-encoding": true,
+	"transfer-encoding": true, "te": true, "upgrade": true, "proxy-connection": true,
+}
+
+// skipTransportHeaders removes the transport headers and the HTTP/2 pseudo-headers
+// (such as ":authority") from recorded headers.
+func skipTransportHeaders(headers []header) []header {
+	var kept []header
+	for _, h := range headers {
+		if !transportHeaders[strings.ToLower(h.name)] && !strings.HasPrefix(h.name, ":") {
+			kept = append(kept, h)
+		}
+	}
+	return kept
+}
+
+// hasHeader reports whether a header is present, ignoring case.
+func hasHeader(headers []header, name string) bool {
+	for _, h := range headers {
+		if strings.EqualFold(h.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagList joins tags into the Tags column, dropping duplicates and the separators
+// they may contain.
+func tagList(tags []string) string {
+	seen := make(map[string]bool)
+	var clean []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(strings.NewReplacer(";", " ", ",", " ").Replace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			clean = append(clean, tag)
+		}
+	}
+	return strings.Join(clean, ";")
+}
+
+// sortedNames returns the keys of a set in alphabetical order.
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
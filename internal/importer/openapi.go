@@ -0,0 +1,452 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-api-testing/internal/openapi"
+	"go-api-testing/models"
+	"io"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxSampleDepth limits how deep generated sample values nest, so that recursive
+// schemas still produce a finite value.
+const maxSampleDepth = 6
+
+// openapiImport holds the state of an OpenAPI import.
+type openapiImport struct {
+	opts      Options
+	testCases []models.TestCase
+	warnings  []Warning
+	disabled  int               // Test cases generated with Run=N.
+	secured   map[string]int    // Operations per security scheme whose credentials must be filled in.
+	schemes   map[string]string // What to fill in, per security scheme.
+}
+
+// ImportOpenAPI generates a starter suite from an OpenAPI 3 document: one test case
+// per operation and documented response code, in path order. The request is built
+// from the examples of the document (or values generated from the schemas, using
+// template expressions such as {{uuid}} or {{fake.email}} where the format allows
+// it), with the required parameters only. The JSON schema of each response becomes
+// the ResponseSchema of its test case. Only the first success response of an
+// operation is enabled: the others need inputs that trigger them, so their test cases
+// are generated with Run=N, ready to be completed.
+//
+// Parameters:
+//   - spec (io.Reader): The OpenAPI document, in YAML or JSON.
+//   - opts (Options): The conversion options; BaseURL replaces the servers of the document.
+//
+// Returns:
+//   - []models.TestCase: The test cases.
+//   - []Warning: What could not be translated, such as unsupported request bodies,
+//     path parameters without an example and credentials to fill in.
+//   - error: An error if the document is not a valid OpenAPI 3 document.
+func ImportOpenAPI(spec io.Reader, opts Options) ([]models.TestCase, []Warning, error) {
+	doc, err := openapi.Parse(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o := &openapiImport{opts: opts, secured: make(map[string]int), schemes: make(map[string]string)}
+	server := opts.BaseURL
+	if server == "" && len(doc.Servers) > 0 {
+		server = doc.Servers[0]
+	}
+	if !strings.Contains(server, "://") {
+		// No server, or a relative one: it is a path prefix on the host serving the document
+		o.warn("", fmt.Sprintf("the document has no absolute server URL, using http://localhost%s (set --base-url)", server))
+		server = "http://localhost" + server
+	}
+	base, prefix := splitURL(strings.TrimSuffix(server, "/"))
+
+	for _, op := range doc.Operations {
+		o.operation(op, base, prefix)
+	}
+
+	if o.disabled > 0 {
+		o.warn("", fmt.Sprintf("%d test case(s) are disabled (Run=N): their responses (errors, alternative successes) need specific inputs", o.disabled))
+	}
+	names := make([]string, 0, len(o.secured))
+	for name := range o.secured {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o.warn("", fmt.Sprintf("%d operation(s) use the %s security scheme: %s", o.secured[name], name, o.schemes[name]))
+	}
+	if len(doc.Unresolved) > 0 {
+		o.warn("", fmt.Sprintf("references that could not be resolved accept any value: %s", strings.Join(doc.Unresolved, ", ")))
+	}
+	return o.testCases, o.warnings, nil
+}
+
+// operation generates the test cases of an operation, one per response code.
+func (o *openapiImport) operation(op openapi.Operation, base, prefix string) {
+	name := op.Name()
+	request := models.TestCase{
+		Method: op.Method,
+		URL:    base,
+		Tags:   tagList(op.Tags),
+	}
+
+	// Required parameters, with their example or a generated value
+	path := op.Path
+	var query, cookies, generated []string
+	var headers []header
+	for _, p := range op.Parameters {
+		if !p.Required {
+			continue
+		}
+		value := p.Example
+		if value == nil {
+			value = sample(p.Schema, 0)
+			if p.In == "path" && !hasExample(p.Schema) {
+				generated = append(generated, p.Name)
+			}
+		}
+		text := scalar(value)
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", escape(text, url.PathEscape))
+		case "query":
+			query = append(query, url.QueryEscape(p.Name)+"="+escape(text, url.QueryEscape))
+		case "header":
+			headers = append(headers, header{p.Name, text})
+		case "cookie":
+			cookies = append(cookies, p.Name+"="+text)
+		}
+	}
+	if len(generated) > 0 {
+		o.warn(name, fmt.Sprintf("no example for the path parameter(s) %s; the generated value may not exist", strings.Join(generated, ", ")))
+	}
+
+	// Credentials: the kind of authentication is set, the secret must be filled in
+	for _, s := range op.Security {
+		switch {
+		case s.Type == "http" && s.Scheme == "bearer", s.Type == "oauth2", s.Type == "openIdConnect":
+			request.Authorization = "Bearer"
+			o.secure(s.Name, "set the token in the User column")
+		case s.Type == "http" && s.Scheme == "basic":
+			request.Authorization = "Basic"
+			o.secure(s.Name, "set the User and Password columns")
+		case s.Type == "apiKey" && s.In == "header":
+			headers = append(headers, header{s.Param, ""})
+			o.secure(s.Name, fmt.Sprintf("set the value of the %s header", s.Param))
+		case s.Type == "apiKey" && s.In == "query":
+			query = append(query, url.QueryEscape(s.Param)+"=")
+			o.secure(s.Name, fmt.Sprintf("set the value of the %s query parameter", s.Param))
+		case s.Type == "apiKey" && s.In == "cookie":
+			cookies = append(cookies, s.Param+"=")
+			o.secure(s.Name, fmt.Sprintf("set the value of the %s cookie", s.Param))
+		default:
+			o.secure(s.Name, "this kind of authentication is not supported, add the credentials by hand")
+		}
+	}
+	if len(cookies) > 0 {
+		headers = append(headers, header{"Cookie", strings.Join(cookies, "; ")})
+	}
+	request.Endpoint = prefix + path
+	if len(query) > 0 {
+		request.Endpoint += "?" + strings.Join(query, "&")
+	}
+
+	// Request body, from the example or generated from the schema
+	if rb := op.RequestBody; rb != nil && len(rb.Content) > 0 {
+		body, contentType, ok := requestBody(rb.Content)
+		if ok {
+			request.Body = body
+			headers = append(headers, header{"Content-Type", contentType})
+		} else {
+			o.warn(name, fmt.Sprintf("%s request bodies are not supported; the request is imported without a body", rb.Content[0].Type))
+		}
+	}
+	var repeated []string
+	request.Headers, repeated = headersJSON(headers)
+	if len(repeated) > 0 {
+		o.warn(name, fmt.Sprintf("only the last value of the repeated headers is kept: %s", strings.Join(repeated, ", ")))
+	}
+
+	// One test case per response code, only the first success enabled
+	enabled := false
+	for _, response := range op.Responses {
+		code, ok := statusCode(response.Code)
+		if !ok {
+			continue
+		}
+		tc := request
+		tc.TestId = testID(o.opts.Prefix, len(o.testCases)+1)
+		tc.TestCase = fmt.Sprintf("%s returns %s", name, response.Code)
+		tc.ExpectedStatusCode = code
+		tc.Run = "N"
+		if code >= 200 && code < 300 && !enabled {
+			tc.Run, enabled = "Y", true
+		} else {
+			o.disabled++
+		}
+		if media, ok := openapi.JSONContent(response.Content); ok && media.Schema != nil {
+			tc.ResponseSchema = marshalJSON(openapi.ForResponse(media.Schema))
+		}
+		o.testCases = append(o.testCases, tc)
+	}
+	if onlyDefault(op.Responses) {
+		tc := request
+		tc.TestId = testID(o.opts.Prefix, len(o.testCases)+1)
+		tc.TestCase = name + " returns 200"
+		tc.Run = "Y"
+		tc.ExpectedStatusCode = 200
+		o.warn(name, "no status code documented, expecting 200")
+		o.testCases = append(o.testCases, tc)
+	}
+}
+
+// secure counts an operation that needs the credentials of a security scheme.
+func (o *openapiImport) secure(scheme, message string) {
+	o.secured[scheme]++
+	o.schemes[scheme] = message
+}
+
+// warn records something that could not be translated.
+func (o *openapiImport) warn(item, message string) {
+	o.warnings = append(o.warnings, Warning{Item: item, Message: message})
+}
+
+// statusCode converts a response code of the document to a status: ranges such as
+// "2XX" give the first status of the range. "default" has no status.
+func statusCode(code string) (int, bool) {
+	if len(code) == 3 && strings.EqualFold(code[1:], "XX") && code[0] >= '1' && code[0] <= '5' {
+		return int(code[0]-'0') * 100, true
+	}
+	status, err := strconv.Atoi(code)
+	return status, err == nil
+}
+
+// onlyDefault reports whether the responses document no status code, e.g. only "default".
+func onlyDefault(responses []openapi.Response) bool {
+	for _, r := range responses {
+		if _, ok := statusCode(r.Code); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// requestBody builds the body of a request, with its content type, preferring JSON
+// over URL-encoded forms and plain text. The result is false for other media types.
+func requestBody(content []openapi.MediaType) (string, string, bool) {
+	if media, ok := openapi.JSONContent(content); ok {
+		value := media.Example
+		if value == nil {
+			value = sample(media.Schema, 0)
+		}
+		return marshalJSON(value), media.Type, true
+	}
+	for _, media := range content {
+		value := media.Example
+		if value == nil {
+			value = sample(media.Schema, 0)
+		}
+		switch strings.ToLower(media.Type) {
+		case "application/x-www-form-urlencoded":
+			fields, _ := value.(map[string]interface{})
+			form := make([]string, 0, len(fields))
+			for _, key := range sortedKeys(fields) {
+				form = append(form, url.QueryEscape(key)+"="+escape(scalar(fields[key]), url.QueryEscape))
+			}
+			return strings.Join(form, "&"), media.Type, true
+		case "text/plain":
+			return scalar(value), media.Type, true
+		}
+	}
+	return "", "", false
+}
+
+// sample builds a value that satisfies a schema, for the parameters and request bodies
+// that have no example: the example, default or first allowed value of the schema,
+// else a value generated from its type and format. Read-only properties are left out.
+func sample(s interface{}, depth int) interface{} {
+	rules, ok := s.(map[string]interface{})
+	if !ok || depth > maxSampleDepth {
+		return nil
+	}
+	for _, key := range []string{"example", "default", "const"} {
+		if value, ok := rules[key]; ok {
+			return value
+		}
+	}
+	for _, key := range []string{"enum", "examples"} {
+		if values, ok := rules[key].([]interface{}); ok && len(values) > 0 {
+			return values[0]
+		}
+	}
+	if all, ok := rules["allOf"].([]interface{}); ok && len(all) > 0 {
+		merged := make(map[string]interface{})
+		for _, sub := range all {
+			value := sample(sub, depth+1)
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return value
+			}
+			for key, v := range obj {
+				merged[key] = v
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alternatives, ok := rules[key].([]interface{}); ok && len(alternatives) > 0 {
+			return sample(alternatives[0], depth+1)
+		}
+	}
+
+	switch schemaType(rules) {
+	case "object":
+		obj := make(map[string]interface{})
+		properties, _ := rules["properties"].(map[string]interface{})
+		for name, property := range properties {
+			if p, ok := property.(map[string]interface{}); ok && p["readOnly"] == true {
+				continue
+			}
+			obj[name] = sample(property, depth+1)
+		}
+		return obj
+	case "array":
+		if item := sample(rules["items"], depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "integer", "number":
+		if min, ok := rules["minimum"].(float64); ok {
+			if rules["exclusiveMinimum"] == true {
+				min++
+			}
+			return math.Ceil(min)
+		}
+		if min, ok := rules["exclusiveMinimum"].(float64); ok {
+			return math.Floor(min) + 1
+		}
+		return float64(1)
+	case "boolean":
+		return true
+	case "string":
+		return sampleString(rules)
+	}
+	return nil
+}
+
+// sampleString generates a string for a schema, using the template expression that
+// produces a value of its format when there is one.
+func sampleString(rules map[string]interface{}) string {
+	switch format, _ := rules["format"].(string); format {
+	case "date-time":
+		return "{{now}}"
+	case "date":
+		return "2024-01-01"
+	case "email":
+		return "{{fake.email}}"
+	case "uuid":
+		return "{{uuid}}"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "192.0.2.1"
+	case "ipv6":
+		return "2001:db8::1"
+	}
+	if _, ok := rules["pattern"]; ok {
+		return "string"
+	}
+	length := 8.0
+	if min, ok := rules["minLength"].(float64); ok && min > length {
+		length = min
+	}
+	if max, ok := rules["maxLength"].(float64); ok && max < length {
+		length = math.Max(max, 1)
+	}
+	return fmt.Sprintf("{{randString %d}}", int(length))
+}
+
+// schemaType returns the type of a schema, ignoring "null" in 3.1 type lists and
+// guessing it from the keywords when it is not given.
+func schemaType(rules map[string]interface{}) string {
+	switch t := rules["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	if _, ok := rules["properties"]; ok {
+		return "object"
+	}
+	if _, ok := rules["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+// hasExample reports whether a schema gives a value to use, rather than just a type.
+func hasExample(s interface{}) bool {
+	rules, _ := s.(map[string]interface{})
+	for _, key := range []string{"example", "default", "const", "enum", "examples"} {
+		if _, ok := rules[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// scalar formats a value for a path, query string, header or form field: strings as
+// they are, lists as comma-separated values and anything else as JSON.
+func scalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = scalar(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return marshalJSON(value)
+}
+
+// escape escapes a value for a URL, leaving template expressions such as {{uuid}}
+// untouched so they are still evaluated.
+func escape(value string, escapeFunc func(string) string) string {
+	if strings.Contains(value, "{{") {
+		return value
+	}
+	return escapeFunc(value)
+}
+
+// marshalJSON encodes a value as compact JSON, without escaping HTML characters.
+func marshalJSON(value interface{}) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// sortedKeys returns the keys of an object in alphabetical order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package importer
+
+import (
+	"go-api-testing/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportOpenAPI(t *testing.T) {
+	cases, warnings, err := ImportOpenAPI(openFixture(t, "openapi.yaml"), Options{Prefix: "OA"})
+	if err != nil {
+		t.Fatalf("ImportOpenAPI() error = %v", err)
+	}
+	const user = `{"properties":{"email":{"format":"email","type":"string"},"id":{"readOnly":true,"type":"integer"},"role":{"enum":["admin","user"],"type":"string"}},"required":["id","email"],"type":"object"}`
+	const body = `{"email":"{{fake.email}}","role":"admin"}`
+	checkTestCases(t, cases, []models.TestCase{
+		{TestId: "OA-001", TestCase: "Upload avatar returns 204", Run: "Y", Method: "PUT", URL: "https://api.example.com", Endpoint: "/v1/avatar", ExpectedStatusCode: 204},
+		{TestId: "OA-002", TestCase: "List users returns 200", Run: "Y", Method: "GET", URL: "https://api.example.com", Endpoint: "/v1/users?page=1",
+			Authorization: "Bearer", ExpectedStatusCode: 200, Tags: "Users", ResponseSchema: `{"items":` + user + `,"type":"array"}`},
+		{TestId: "OA-003", TestCase: "List users returns 401", Run: "N", Method: "GET", URL: "https://api.example.com", Endpoint: "/v1/users?page=1",
+			Authorization: "Bearer", ExpectedStatusCode: 401, Tags: "Users"},
+		{TestId: "OA-004", TestCase: "createUser returns 201", Run: "Y", Method: "POST", URL: "https://api.example.com", Endpoint: "/v1/users",
+			Authorization: "Bearer", Headers: `{"Content-Type":"application/json"}`, Body: body, ExpectedStatusCode: 201, Tags: "Users", ResponseSchema: user},
+		{TestId: "OA-005", TestCase: "createUser returns 4XX", Run: "N", Method: "POST", URL: "https://api.example.com", Endpoint: "/v1/users",
+			Authorization: "Bearer", Headers: `{"Content-Type":"application/json"}`, Body: body, ExpectedStatusCode: 400, Tags: "Users"},
+		{TestId: "OA-006", TestCase: "Get user returns 200", Run: "Y", Method: "GET", URL: "https://api.example.com", Endpoint: "/v1/users/{{uuid}}",
+			Headers: `{"X-Api-Key":""}`, ExpectedStatusCode: 200, Tags: "Users;Admin"},
+	})
+
+	wantWarnings := []string{
+		"Upload avatar: image/png request bodies are not supported; the request is imported without a body",
+		"Get user: no example for the path parameter(s) id; the generated value may not exist",
+		"Get user: no status code documented, expecting 200",
+		"2 test case(s) are disabled (Run=N): their responses (errors, alternative successes) need specific inputs",
+		"2 operation(s) use the bearer security scheme: set the token in the User column",
+		"1 operation(s) use the key security scheme: set the value of the X-Api-Key header",
+	}
+	if got := warningStrings(warnings); !reflect.DeepEqual(got, wantWarnings) {
+		t.Errorf("warnings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantWarnings, "\n"))
+	}
+}
+
+func TestImportOpenAPIBaseURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		baseURL      string
+		wantURL      string
+		wantEndpoint string
+	}{
+		{"absolute", "http://localhost:8080/api", "http://localhost:8080", "/api/avatar"},
+		{"relative", "/api", "http://localhost", "/api/avatar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, _, err := ImportOpenAPI(openFixture(t, "openapi.yaml"), Options{BaseURL: tt.baseURL})
+			if err != nil {
+				t.Fatalf("ImportOpenAPI() error = %v", err)
+			}
+			if cases[0].TestId != "TC-001" || cases[0].URL != tt.wantURL || cases[0].Endpoint != tt.wantEndpoint {
+				t.Errorf("first test case = %+v, want %s%s", cases[0], tt.wantURL, tt.wantEndpoint)
+			}
+		})
+	}
+}
+
+func TestImportOpenAPIErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"invalid YAML", "openapi: [3"},
+		{"Swagger 2", "swagger: \"2.0\"\npaths: {}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ImportOpenAPI(strings.NewReader(tt.spec), Options{}); err == nil {
+				t.Errorf("ImportOpenAPI() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,542 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api-testing/models"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// postmanCollection is the subset of the Postman Collection Format v2.1 that is translated.
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth"`
+	Event    []postmanEvent    `json:"event"`
+	Variable []postmanVariable `json:"variable"`
+}
+
+// postmanItem is either a folder (with Item) or a request.
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Item     []postmanItem     `json:"item"`
+	Request  *postmanRequest   `json:"request"`
+	Response []postmanResponse `json:"response"`
+	Auth     *postmanAuth      `json:"auth"`
+	Event    []postmanEvent    `json:"event"`
+}
+
+type postmanRequest struct {
+	Method string       `json:"method"`
+	Header []postmanKV  `json:"header"`
+	URL    postmanURL   `json:"url"`
+	Body   *postmanBody `json:"body"`
+	Auth   *postmanAuth `json:"auth"`
+}
+
+// UnmarshalJSON accepts the short form of a request, a plain URL string.
+func (r *postmanRequest) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		r.Method, r.URL.Raw = "GET", raw
+		return nil
+	}
+	type request postmanRequest
+	return json.Unmarshal(data, (*request)(r))
+}
+
+type postmanURL struct {
+	Raw      string      `json:"raw"`
+	Protocol string      `json:"protocol"`
+	Host     []string    `json:"host"`
+	Port     string      `json:"port"`
+	Path     []string    `json:"path"`
+	Query    []postmanKV `json:"query"`
+	Variable []postmanKV `json:"variable"`
+}
+
+// UnmarshalJSON accepts URLs given as a plain string.
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	type structured postmanURL
+	return json.Unmarshal(data, (*structured)(u))
+}
+
+type postmanKV struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Type     string `json:"type"`
+}
+
+type postmanBody struct {
+	Mode       string      `json:"mode"`
+	Raw        string      `json:"raw"`
+	URLEncoded []postmanKV `json:"urlencoded"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+	Disabled bool `json:"disabled"`
+}
+
+type postmanAuth struct {
+	Type   string      `json:"type"`
+	Basic  []postmanKV `json:"basic"`
+	Bearer []postmanKV `json:"bearer"`
+	APIKey []postmanKV `json:"apikey"`
+}
+
+// param returns the value of a parameter of the auth method, e.g. "username" of basic auth.
+func (a *postmanAuth) param(params []postmanKV, key string) string {
+	for _, p := range params {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+type postmanEvent struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Exec postmanLines `json:"exec"`
+	} `json:"script"`
+}
+
+// postmanLines holds the lines of a script, which may be given as a list or a single string.
+type postmanLines []string
+
+// UnmarshalJSON accepts a single string as well as a list of lines.
+func (l *postmanLines) UnmarshalJSON(data []byte) error {
+	var line string
+	if err := json.Unmarshal(data, &line); err == nil {
+		*l = strings.Split(line, "\n")
+		return nil
+	}
+	var lines []string
+	err := json.Unmarshal(data, &lines)
+	*l = lines
+	return err
+}
+
+type postmanResponse struct {
+	Code int `json:"code"`
+}
+
+type postmanVariable struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Disabled bool        `json:"disabled"`
+	Enabled  *bool       `json:"enabled"` // Used by environment files.
+}
+
+// postmanEnvironment is a Postman environment export.
+type postmanEnvironment struct {
+	Name   string            `json:"name"`
+	Values []postmanVariable `json:"values"`
+}
+
+// postmanDynamic maps the Postman dynamic variables to the equivalent template expressions.
+var postmanDynamic = map[string]string{
+	"$guid":                "{{uuid}}",
+	"$randomUUID":          "{{uuid}}",
+	"$timestamp":           "{{now unix}}",
+	"$isoTimestamp":        "{{now}}",
+	"$randomInt":           "{{randInt 0 1000}}",
+	"$randomAlphaNumeric":  "{{randString 1}}",
+	"$randomEmail":         "{{fake.email}}",
+	"$randomExampleEmail":  "{{fake.email}}",
+	"$randomFirstName":     "{{fake.firstName}}",
+	"$randomLastName":      "{{fake.lastName}}",
+	"$randomFullName":      "{{fake.name}}",
+	"$randomPhoneNumber":   "{{fake.phone}}",
+	"$randomCity":          "{{fake.city}}",
+	"$randomStreetAddress": "{{fake.street}}",
+}
+
+var (
+	// postmanVariablePattern matches a {{variable}} reference.
+	postmanVariablePattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+	// statusChecks match the status code assertions of test scripts that can be translated.
+	statusChecks = []*regexp.Regexp{
+		regexp.MustCompile(`pm\.response\.to\.(?:have|be)\.status\(\s*(\d{3})\s*\)`),
+		regexp.MustCompile(`pm\.expect\(\s*pm\.response\.code\s*\)\.to\.(?:be\.)?(?:eql|equal|equals|eq)\(\s*(\d{3})\s*\)`),
+		regexp.MustCompile(`responseCode\.code\s*===?\s*(\d{3})`),
+	}
+	// okCheck matches pm.response.to.be.ok, which Postman defines as status 200.
+	okCheck = regexp.MustCompile(`pm\.response\.to\.be\.ok\b`)
+	// assertion matches any assertion of a test script, to count the untranslated ones.
+	assertion = regexp.MustCompile(`pm\.expect\(|pm\.response\.to\.|tests\[`)
+)
+
+// postmanImport holds the state of the conversion of a collection.
+type postmanImport struct {
+	opts      Options
+	variables map[string]string
+	testCases []models.TestCase
+	warnings  []Warning
+}
+
+// ImportPostman converts a Postman Collection v2.1 into test cases.
+// Folders become tags, and each request becomes a test case with its method, URL,
+// headers, body and authentication. Collection and environment variables are
+// replaced by their values (the environment wins), and Postman dynamic variables
+// such as {{$guid}} become the equivalent template expressions. The expected status
+// comes from recognizable status checks of the test scripts (pm.response.to.have.status,
+// pm.expect(pm.response.code)...), else from the first saved example, else 200.
+//
+// Parameters:
+//   - collection (io.Reader): The collection, exported as JSON.
+//   - environment (io.Reader): An exported environment, or nil.
+//   - opts (Options): The conversion options.
+//
+// Returns:
+//   - []models.TestCase: The test cases, in collection order.
+//   - []Warning: What could not be translated, such as scripts, unsupported auth
+//     methods or bodies, and variables without a value.
+//   - error: An error if the files are not valid Postman exports.
+func ImportPostman(collection, environment io.Reader, opts Options) ([]models.TestCase, []Warning, error) {
+	var c postmanCollection
+	if err := json.NewDecoder(collection).Decode(&c); err != nil {
+		return nil, nil, fmt.Errorf("error parsing Postman collection: %v", err)
+	}
+	if c.Info.Schema != "" && !strings.Contains(c.Info.Schema, "v2.1") && !strings.Contains(c.Info.Schema, "v2.0") {
+		return nil, nil, fmt.Errorf("unsupported Postman collection schema %s (export it as Collection v2.1)", c.Info.Schema)
+	}
+
+	p := &postmanImport{opts: opts, variables: make(map[string]string)}
+	for _, v := range c.Variable {
+		if !v.Disabled {
+			p.variables[v.Key] = variableValue(v.Value)
+		}
+	}
+	if environment != nil {
+		var env postmanEnvironment
+		if err := json.NewDecoder(environment).Decode(&env); err != nil {
+			return nil, nil, fmt.Errorf("error parsing Postman environment: %v", err)
+		}
+		for _, v := range env.Values {
+			if v.Enabled == nil || *v.Enabled {
+				p.variables[v.Key] = variableValue(v.Value)
+			}
+		}
+	}
+	if scriptLines(c.Event, "prerequest") != "" {
+		p.warn("", "the collection pre-request script is not translated")
+	}
+
+	missing := make(map[string]bool)
+	p.walk(c.Item, nil, c.Auth, scriptLines(c.Event, "test"), missing)
+	if len(missing) > 0 {
+		p.warn("", fmt.Sprintf("variables without a value were left as {{name}}: %s (define them or set them in the test cases)",
+			strings.Join(sortedNames(missing), ", ")))
+	}
+	return p.testCases, p.warnings, nil
+}
+
+// walk converts the requests of a list of items, descending into folders.
+// Folders pass their name (as a tag), authentication and test script to their items.
+func (p *postmanImport) walk(items []postmanItem, folders []string, auth *postmanAuth, tests string, missing map[string]bool) {
+	for _, item := range items {
+		itemAuth := auth
+		if item.Auth != nil && item.Auth.Type != "inherit" {
+			itemAuth = item.Auth
+		}
+		if item.Request != nil && item.Request.Auth != nil && item.Request.Auth.Type != "inherit" {
+			itemAuth = item.Request.Auth
+		}
+		itemTests := tests + scriptLines(item.Event, "test")
+
+		if item.Request == nil {
+			path := append(append([]string(nil), folders...), item.Name)
+			p.walk(item.Item, path, itemAuth, itemTests, missing)
+			continue
+		}
+		name := strings.Join(append(append([]string(nil), folders...), item.Name), " / ")
+		if scriptLines(item.Event, "prerequest") != "" {
+			p.warn(name, "the pre-request script is not translated")
+		}
+		p.testCases = append(p.testCases, p.request(name, item, folders, itemAuth, itemTests, missing))
+	}
+}
+
+// request converts a single request into a test case.
+func (p *postmanImport) request(name string, item postmanItem, folders []string, auth *postmanAuth, tests string, missing map[string]bool) models.TestCase {
+	req := item.Request
+	resolve := func(s string) string { return p.resolve(s, missing) }
+	tc := models.TestCase{
+		TestId:   testID(p.opts.Prefix, len(p.testCases)+1),
+		TestCase: item.Name,
+		Run:      "Y",
+		Method:   strings.ToUpper(req.Method),
+		Tags:     tagList(folders),
+	}
+	if tc.Method == "" {
+		tc.Method = "GET"
+	}
+
+	// Headers, skipping the disabled ones
+	var headers []header
+	for _, h := range req.Header {
+		if !h.Disabled {
+			headers = append(headers, header{resolve(h.Key), resolve(h.Value)})
+		}
+	}
+
+	// URL, with path variables (":id") replaced by their values
+	rawURL := postmanRawURL(req.URL)
+	for _, v := range req.URL.Variable {
+		value := v.Value
+		if value == "" {
+			value = "{{" + v.Key + "}}"
+		}
+		rawURL = replacePathVariable(rawURL, v.Key, value)
+	}
+	rawURL = resolve(rawURL)
+	if !strings.Contains(rawURL, "://") && !strings.HasPrefix(rawURL, "{{") {
+		rawURL = "http://" + rawURL // Postman assumes http when the protocol is omitted
+	}
+
+	// Authentication
+	if auth != nil {
+		switch auth.Type {
+		case "noauth", "":
+		case "basic":
+			tc.Authorization = "Basic"
+			tc.User = resolve(auth.param(auth.Basic, "username"))
+			tc.Password = resolve(auth.param(auth.Basic, "password"))
+		case "bearer":
+			tc.Authorization = "Bearer"
+			tc.User = resolve(auth.param(auth.Bearer, "token"))
+		case "apikey":
+			key, value := resolve(auth.param(auth.APIKey, "key")), resolve(auth.param(auth.APIKey, "value"))
+			if auth.param(auth.APIKey, "in") == "query" {
+				rawURL = addQueryParam(rawURL, key, value)
+			} else {
+				headers = append(headers, header{key, value})
+			}
+		default:
+			p.warn(name, fmt.Sprintf("%s authentication is not supported; add the credentials to the test case", auth.Type))
+		}
+	}
+	tc.URL, tc.Endpoint = splitURL(rawURL)
+
+	// Body
+	if body := req.Body; body != nil && !body.Disabled {
+		contentType := ""
+		switch body.Mode {
+		case "raw":
+			tc.Body = resolve(body.Raw)
+			switch body.Options.Raw.Language {
+			case "json":
+				contentType = "application/json"
+			case "xml":
+				contentType = "application/xml"
+			case "html":
+				contentType = "text/html"
+			case "text":
+				contentType = "text/plain"
+			}
+		case "urlencoded":
+			form := url.Values{}
+			for _, kv := range body.URLEncoded {
+				if !kv.Disabled {
+					form.Add(resolve(kv.Key), resolve(kv.Value))
+				}
+			}
+			tc.Body, contentType = form.Encode(), "application/x-www-form-urlencoded"
+		case "graphql":
+			if body.GraphQL != nil {
+				payload := map[string]interface{}{"query": resolve(body.GraphQL.Query)}
+				if vars := strings.TrimSpace(resolve(body.GraphQL.Variables)); vars != "" {
+					payload["variables"] = json.RawMessage(vars)
+				}
+				if b, err := json.Marshal(payload); err == nil {
+					tc.Body, contentType = string(b), "application/json"
+				} else {
+					p.warn(name, "the GraphQL variables are not valid JSON")
+				}
+			}
+		case "":
+		default:
+			p.warn(name, fmt.Sprintf("%s bodies are not supported; the request is imported without a body", body.Mode))
+		}
+		if contentType != "" && tc.Body != "" && !hasHeader(headers, "Content-Type") {
+			headers = append(headers, header{"Content-Type", contentType})
+		}
+	}
+
+	var repeated []string
+	tc.Headers, repeated = headersJSON(headers)
+	if len(repeated) > 0 {
+		p.warn(name, fmt.Sprintf("only the last value of the repeated headers is kept: %s", strings.Join(repeated, ", ")))
+	}
+
+	// Expected status, from the test scripts or the saved examples
+	status, untranslated := statusFromScript(tests)
+	switch {
+	case status != 0:
+		tc.ExpectedStatusCode = status
+	case len(item.Response) > 0 && item.Response[0].Code != 0:
+		tc.ExpectedStatusCode = item.Response[0].Code
+	default:
+		tc.ExpectedStatusCode = 200
+		p.warn(name, "no status check found, expecting 200")
+	}
+	if untranslated > 0 {
+		p.warn(name, fmt.Sprintf("%d test script assertion(s) not translated", untranslated))
+	}
+	return tc
+}
+
+// resolve replaces the known variables of s by their values, which may refer to other
+// variables, and the dynamic variables by template expressions. Unknown variables are
+// left as they are and recorded in missing.
+func (p *postmanImport) resolve(s string, missing map[string]bool) string {
+	return p.resolveDepth(s, missing, 0)
+}
+
+// resolveDepth implements resolve, giving up on values nested too deep (e.g. a variable
+// that refers to itself).
+func (p *postmanImport) resolveDepth(s string, missing map[string]bool, depth int) string {
+	return postmanVariablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := postmanVariablePattern.FindStringSubmatch(match)[1]
+		if value, ok := p.variables[name]; ok {
+			if depth < 10 {
+				return p.resolveDepth(value, missing, depth+1)
+			}
+			return value
+		}
+		if expr, ok := postmanDynamic[name]; ok {
+			return expr
+		}
+		if strings.HasPrefix(name, "$") {
+			p.warn("", fmt.Sprintf("dynamic variable {{%s}} has no equivalent and was left as it is", name))
+		} else {
+			missing[name] = true
+		}
+		return match
+	})
+}
+
+// warn records a warning, once.
+func (p *postmanImport) warn(item, message string) {
+	w := Warning{Item: item, Message: message}
+	for _, existing := range p.warnings {
+		if existing == w {
+			return
+		}
+	}
+	p.warnings = append(p.warnings, w)
+}
+
+// postmanRawURL returns the URL of a request as text, building it from its parts
+// when the raw form is missing.
+func postmanRawURL(u postmanURL) string {
+	if u.Raw != "" {
+		return u.Raw
+	}
+	var b strings.Builder
+	if u.Protocol != "" {
+		b.WriteString(u.Protocol + "://")
+	}
+	b.WriteString(strings.Join(u.Host, "."))
+	if u.Port != "" {
+		b.WriteString(":" + u.Port)
+	}
+	if len(u.Path) > 0 {
+		b.WriteString("/" + strings.Join(u.Path, "/"))
+	}
+	var query []string
+	for _, q := range u.Query {
+		if !q.Disabled {
+			query = append(query, q.Key+"="+q.Value)
+		}
+	}
+	if len(query) > 0 {
+		b.WriteString("?" + strings.Join(query, "&"))
+	}
+	return b.String()
+}
+
+// replacePathVariable replaces the path segment ":name" of a URL by value.
+func replacePathVariable(rawURL, name, value string) string {
+	pattern := regexp.MustCompile(`/:` + regexp.QuoteMeta(name) + `([/?#]|$)`)
+	return pattern.ReplaceAllString(rawURL, "/"+strings.ReplaceAll(value, "$", "$$")+"$1")
+}
+
+// addQueryParam appends a query parameter to a URL.
+func addQueryParam(rawURL, key, value string) string {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + url.QueryEscape(key) + "=" + url.QueryEscape(value)
+}
+
+// scriptLines returns the script of the events listening to the given event type.
+func scriptLines(events []postmanEvent, listen string) string {
+	var b strings.Builder
+	for _, e := range events {
+		if e.Listen == listen {
+			for _, line := range e.Script.Exec {
+				if strings.TrimSpace(line) != "" && !strings.HasPrefix(strings.TrimSpace(line), "//") {
+					b.WriteString(line + "\n")
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// statusFromScript finds the expected status in a test script. It returns the status
+// (0 if none is recognized; the last one wins, so request scripts override those of
+// their folders) and the number of other assertions, which cannot be translated.
+func statusFromScript(script string) (status, untranslated int) {
+	for _, line := range strings.Split(script, "\n") {
+		recognized := false
+		for _, check := range statusChecks {
+			if m := check.FindStringSubmatch(line); m != nil {
+				status, _ = strconv.Atoi(m[1])
+				recognized = true
+			}
+		}
+		if !recognized && okCheck.MatchString(line) {
+			status, recognized = 200, true
+		}
+		if !recognized {
+			untranslated += len(assertion.FindAllString(line, -1))
+		}
+	}
+	return status, untranslated
+}
+
+// variableValue formats the value of a variable, which may be of any JSON type.
+func variableValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		b, _ := json.Marshal(value)
+		return string(b)
+	}
+}
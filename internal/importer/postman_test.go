@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"go-api-testing/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// openFixture opens a file of the testdata directory.
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+// warningStrings formats warnings as "item: message" for comparison.
+func warningStrings(warnings []Warning) []string {
+	var out []string
+	for _, w := range warnings {
+		out = append(out, w.String())
+	}
+	return out
+}
+
+// checkTestCases compares imported test cases with the expected ones, field by field.
+func checkTestCases(t *testing.T, got, want []models.TestCase) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("imported %d test cases, want %d:\n%+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("test case %d =\n%+v\nwant\n%+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestImportPostman(t *testing.T) {
+	cases, warnings, err := ImportPostman(openFixture(t, "postman_collection.json"), openFixture(t, "postman_environment.json"), Options{Prefix: "PM"})
+	if err != nil {
+		t.Fatalf("ImportPostman() error = %v", err)
+	}
+	checkTestCases(t, cases, []models.TestCase{
+		{TestId: "PM-001", TestCase: "List users", Run: "Y", Method: "GET", URL: "https://api.example.com", Endpoint: "/v2/users?page=1",
+			Authorization: "Bearer", User: "env-token", Headers: `{"Accept":"application/json"}`, ExpectedStatusCode: 200, Tags: "Users"},
+		{TestId: "PM-002", TestCase: "Create user", Run: "Y", Method: "POST", URL: "https://api.example.com", Endpoint: "/v2/users",
+			Authorization: "Bearer", User: "env-token", Headers: `{"Content-Type":"application/json","X-Request-Id":"{{uuid}}","X-Tenant":"{{tenant}}"}`,
+			Body: `{"email": "{{fake.email}}", "team": "{{team}}"}`, ExpectedStatusCode: 201, Tags: "Users"},
+		{TestId: "PM-003", TestCase: "Get user", Run: "Y", Method: "GET", URL: "https://api.example.com", Endpoint: "/v2/users/42?api_key=k-123",
+			ExpectedStatusCode: 404, Tags: "Users"},
+		{TestId: "PM-004", TestCase: "Login", Run: "Y", Method: "POST", URL: "https://api.example.com", Endpoint: "/auth/token",
+			Authorization: "Basic", User: "ann", Password: "{{password}}", Headers: `{"Content-Type":"application/x-www-form-urlencoded"}`,
+			Body: "grant_type=password", ExpectedStatusCode: 200},
+		{TestId: "PM-005", TestCase: "Upload avatar", Run: "Y", Method: "PUT", URL: "http://api.example.com", Endpoint: "/avatar", ExpectedStatusCode: 200},
+		{TestId: "PM-006", TestCase: "Health", Run: "Y", Method: "GET", URL: "https://api.example.com", Endpoint: "/health",
+			Authorization: "Bearer", User: "env-token", ExpectedStatusCode: 200},
+	})
+
+	wantWarnings := []string{
+		"Users / Create user: the pre-request script is not translated",
+		"Users / Create user: only the last value of the repeated headers is kept: X-Tenant",
+		"Users / Create user: 1 test script assertion(s) not translated",
+		"Login: 1 test script assertion(s) not translated",
+		"Upload avatar: oauth2 authentication is not supported; add the credentials to the test case",
+		"Upload avatar: formdata bodies are not supported; the request is imported without a body",
+		"Upload avatar: no status check found, expecting 200",
+		"Health: no status check found, expecting 200",
+		"variables without a value were left as {{name}}: password, team, tenant (define them or set them in the test cases)",
+	}
+	if got := warningStrings(warnings); !reflect.DeepEqual(got, wantWarnings) {
+		t.Errorf("warnings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantWarnings, "\n"))
+	}
+}
+
+func TestImportPostmanWithoutEnvironment(t *testing.T) {
+	cases, warnings, err := ImportPostman(openFixture(t, "postman_collection.json"), nil, Options{})
+	if err != nil {
+		t.Fatalf("ImportPostman() error = %v", err)
+	}
+	if cases[0].TestId != "TC-001" || cases[0].URL != "https://staging.example.com" || cases[0].User != "{{token}}" || cases[3].URL != "{{authUrl}}" {
+		t.Errorf("test cases without environment = %+v", cases)
+	}
+	if last := warnings[len(warnings)-1].Message; !strings.Contains(last, "apiKey, authUrl, password, team, tenant, token") {
+		t.Errorf("missing variables warning = %q", last)
+	}
+}
+
+func TestImportPostmanErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		collection  string
+		environment string
+		wantErr     string
+	}{
+		{"invalid collection", `{"item":`, "", "error parsing Postman collection"},
+		{"old schema", `{"info":{"schema":"https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}}`, "", "unsupported Postman collection schema"},
+		{"invalid environment", `{"item":[]}`, `[`, "error parsing Postman environment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var environment *strings.Reader
+			if tt.environment != "" {
+				environment = strings.NewReader(tt.environment)
+			}
+			var err error
+			if environment != nil {
+				_, _, err = ImportPostman(strings.NewReader(tt.collection), environment, Options{})
+			} else {
+				_, _, err = ImportPostman(strings.NewReader(tt.collection), nil, Options{})
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ImportPostman() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
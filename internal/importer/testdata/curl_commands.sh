@@ -0,0 +1,16 @@
+# Commands copied from tickets and the browser developer tools
+curl 'https://api.example.com/v1/users?page=1' \
+  -H 'Accept: application/json' \
+  -H 'Accept-Encoding: gzip, deflate, br' \
+  -H "Authorization: Bearer $TOKEN" \
+  --compressed -sSL
+
+curl -XPOST https://api.example.com/v1/users --json '{"name": "Ann O'\''Neil"}' -u ann:secret | jq .
+
+curl -G api.example.com/search --data-urlencode 'q=a b&c' -d limit=5 -o out.json
+
+curl -X PUT 'https://bob:pw@api.example.com/v1/avatar' -F 'file=@avatar.png' -b session=abc -d @body.json
+
+curl --url https://api.example.com/v1/notes --data $'line1\nline2' -H 'X-Id: 1' -H 'X-Id: 2' --oauth2-bearer tok -u ann
+
+curl -s --verbose
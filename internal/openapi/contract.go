@@ -0,0 +1,246 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api-testing/internal/api"
+	"go-api-testing/internal/schema"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Rules broken by an exchange, as reported in Violation.Rule.
+const (
+	RuleOperation    = "operation"     // The path or method is not documented.
+	RuleParameter    = "parameter"     // A required parameter is missing.
+	RuleRequestBody  = "request body"  // The request body is missing or does not match its schema.
+	RuleStatus       = "status"        // The status code is not documented.
+	RuleResponseBody = "response body" // The response body does not match its schema.
+)
+
+// Violation is a way in which an HTTP exchange breaks the contract of the API.
+type Violation struct {
+	Rule    string `json:"rule"`    // Broken rule, one of the Rule constants.
+	Message string `json:"message"` // Description of the violation.
+}
+
+// String returns the violation as "rule: message".
+func (v Violation) String() string {
+	return v.Rule + ": " + v.Message
+}
+
+// Check validates an exchange against the contract: the path and method must be
+// documented, the required parameters and request body present, the status code
+// documented and the JSON bodies must match their schemas.
+//
+// Parameters:
+//   - exchange (api.Exchange): The request sent and the response received.
+//
+// Returns:
+//   - []Violation: The violations found; empty when the exchange honours the contract.
+func (d *Document) Check(exchange api.Exchange) []Violation {
+	req, resp := exchange.Request, exchange.Response
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return []Violation{{RuleOperation, fmt.Sprintf("invalid request URL %q: %v", req.URL, err)}}
+	}
+
+	op, ok := d.Find(req.Method, u.EscapedPath())
+	if !ok {
+		if path, found := d.findPath(u.EscapedPath()); found {
+			return []Violation{{RuleOperation, fmt.Sprintf("%s %s is not documented (the path has no %s operation)", req.Method, path, req.Method)}}
+		}
+		return []Violation{{RuleOperation, fmt.Sprintf("%s %s is not documented", req.Method, u.Path)}}
+	}
+	var violations []Violation
+
+	// Required parameters; path parameters are always present once the path matched
+	query := u.Query()
+	for _, p := range op.Parameters {
+		if !p.Required {
+			continue
+		}
+		present := true
+		switch p.In {
+		case "query":
+			_, present = query[p.Name]
+		case "header":
+			present = req.Headers.Get(p.Name) != ""
+		case "cookie":
+			_, err := (&http.Request{Header: req.Headers}).Cookie(p.Name)
+			present = err == nil
+		}
+		if !present {
+			violations = append(violations, Violation{RuleParameter, fmt.Sprintf("missing required %s parameter %q", p.In, p.Name)})
+		}
+	}
+
+	// Request body
+	if rb := op.RequestBody; rb != nil {
+		if strings.TrimSpace(req.Body) == "" {
+			if rb.Required {
+				violations = append(violations, Violation{RuleRequestBody, "the request body is required"})
+			}
+		} else {
+			violations = append(violations, checkBody(RuleRequestBody, rb.Content, req.Headers.Get("Content-Type"), req.Body, ForRequest)...)
+		}
+	}
+
+	// Status code and response body
+	response, ok := op.response(resp.StatusCode)
+	if !ok {
+		documented := make([]string, len(op.Responses))
+		for i, r := range op.Responses {
+			documented[i] = r.Code
+		}
+		return append(violations, Violation{RuleStatus, fmt.Sprintf("status %d is not documented for %s %s (documented: %s)",
+			resp.StatusCode, op.Method, op.Path, strings.Join(documented, ", "))})
+	}
+	if len(response.Content) > 0 && strings.TrimSpace(resp.Body) != "" {
+		violations = append(violations, checkBody(RuleResponseBody, response.Content, resp.Headers.Get("Content-Type"), resp.Body, ForResponse)...)
+	}
+	return violations
+}
+
+// Find returns the operation serving a method and a request path, which may include
+// the path of the server URL (e.g. "/v1/users/7" for the template "/users/{id}" of
+// the server "https://api.example.com/v1"). Literal path segments win over templated
+// ones, so "/users/me" is preferred to "/users/{id}".
+//
+// Parameters:
+//   - method (string): The HTTP method of the request.
+//   - path (string): The escaped path of the request URL.
+//
+// Returns:
+//   - Operation: The operation found.
+//   - bool: Whether the request matches an operation.
+func (d *Document) Find(method, path string) (Operation, bool) {
+	var best Operation
+	bestScore := -1
+	for _, op := range d.Operations {
+		if !strings.EqualFold(op.Method, method) {
+			continue
+		}
+		if score, ok := d.match(op, path); ok && score > bestScore {
+			best, bestScore = op, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// findPath returns the path template that matches a request path, whatever the method.
+func (d *Document) findPath(path string) (string, bool) {
+	for _, op := range d.Operations {
+		if _, ok := d.match(op, path); ok {
+			return op.Path, true
+		}
+	}
+	return "", false
+}
+
+// match reports whether a request path matches the path of an operation, with or
+// without the path of a server. The score is the number of literal characters of the
+// path template, to prefer the most specific template.
+func (d *Document) match(op Operation, path string) (int, bool) {
+	candidates := []string{path}
+	for _, prefix := range d.prefixes {
+		if rest, ok := strings.CutPrefix(path, prefix); ok {
+			candidates = append(candidates, rest)
+		}
+	}
+	for _, candidate := range candidates {
+		if op.pattern.MatchString(candidate) {
+			return len(templateParameter.ReplaceAllString(op.Path, "")), true
+		}
+	}
+	return 0, false
+}
+
+// templateParameter matches the parameters of a path template, e.g. "{id}".
+var templateParameter = regexp.MustCompile(`\{[^/{}]+\}`)
+
+// templatePattern compiles a path template into a regular expression where each
+// parameter matches one path segment, or part of one (e.g. "/files/{name}.json").
+func templatePattern(template string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range templateParameter.FindAllStringIndex(template, -1) {
+		b.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		b.WriteString("[^/]+")
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(template[last:]))
+	b.WriteString("/?$")
+	return regexp.MustCompile(b.String())
+}
+
+// response returns the documented response of a status code: the exact code, else
+// its range (e.g. "4XX"), else "default".
+func (o Operation) response(status int) (Response, bool) {
+	for _, code := range []string{fmt.Sprint(status), fmt.Sprintf("%dXX", status/100), "default"} {
+		for _, r := range o.Responses {
+			if strings.EqualFold(r.Code, code) {
+				return r, true
+			}
+		}
+	}
+	return Response{}, false
+}
+
+// checkBody checks that a body has a documented media type and, for JSON bodies with
+// a schema, that it matches the schema once adapted to the direction of the body.
+func checkBody(rule string, content []MediaType, contentType, body string, adapt func(interface{}) interface{}) []Violation {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+	media, ok := mediaTypeFor(content, mediaType)
+	if !ok {
+		documented := make([]string, len(content))
+		for i, m := range content {
+			documented[i] = m.Type
+		}
+		if mediaType == "" {
+			mediaType = "no Content-Type"
+		}
+		return []Violation{{rule, fmt.Sprintf("%s is not documented (documented: %s)", mediaType, strings.Join(documented, ", "))}}
+	}
+	if media.Schema == nil || !IsJSON(media.Type) && !IsJSON(mediaType) {
+		return nil // Only JSON bodies are validated
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return []Violation{{rule, fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	var violations []Violation
+	for _, v := range schema.Validate(adapt(media.Schema), value) {
+		violations = append(violations, Violation{rule, v.String()})
+	}
+	return violations
+}
+
+// mediaTypeFor returns the documented media type matching a Content-Type: the exact
+// type, else a wildcard such as "application/*" or "*/*". Without a Content-Type,
+// the only documented media type is assumed.
+func mediaTypeFor(content []MediaType, mediaType string) (MediaType, bool) {
+	if mediaType == "" {
+		if len(content) == 1 {
+			return content[0], true
+		}
+		return MediaType{}, false
+	}
+	major, _, _ := strings.Cut(mediaType, "/")
+	for _, candidate := range []string{mediaType, major + "/*", "*/*"} {
+		for _, m := range content {
+			if documented, _, err := mime.ParseMediaType(m.Type); err == nil && strings.EqualFold(documented, candidate) {
+				return m, true
+			}
+		}
+	}
+	return MediaType{}, false
+}
@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"go-api-testing/internal/api"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// loadFixture loads an OpenAPI document of the testdata directory.
+func loadFixture(t *testing.T, name string) *Document {
+	t.Helper()
+	doc, err := Load(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return doc
+}
+
+// exchange builds an exchange, with the Content-Type of each body when it is not empty.
+func exchange(method, url, requestType, requestBody string, status int, responseType, responseBody string) api.Exchange {
+	req := api.Request{Method: method, URL: url, Headers: http.Header{}, Body: requestBody}
+	if requestType != "" {
+		req.Headers.Set("Content-Type", requestType)
+	}
+	resp := api.Response{StatusCode: status, Headers: http.Header{}, Body: responseBody}
+	if responseType != "" {
+		resp.Headers.Set("Content-Type", responseType)
+	}
+	return api.Exchange{Request: req, Response: resp}
+}
+
+func TestCheck(t *testing.T) {
+	doc := loadFixture(t, "contract.yaml")
+	const base = "https://api.example.com/v1"
+	const user = `{"id": 1, "email": "ann@example.com"}`
+	withHeaders := func(e api.Exchange, headers ...string) api.Exchange {
+		for i := 0; i < len(headers); i += 2 {
+			e.Request.Headers.Set(headers[i], headers[i+1])
+		}
+		return e
+	}
+
+	tests := []struct {
+		name     string
+		exchange api.Exchange
+		want     []string // Violations as "rule: message".
+	}{
+		{"list honours the contract", withHeaders(exchange("GET", base+"/users?page=1", "", "", 200, "application/json", "["+user+"]"), "X-Tenant", "acme", "Cookie", "session=abc"), nil},
+		{"missing parameters", exchange("GET", base+"/users", "", "", 200, "application/json", "[]"), []string{
+			`parameter: missing required query parameter "page"`,
+			`parameter: missing required header parameter "X-Tenant"`,
+			`parameter: missing required cookie parameter "session"`,
+		}},
+		{"read-only property not required in requests", exchange("POST", base+"/users", "application/json", `{"email": "ann@example.com", "password": "secret"}`, 201, "application/json; charset=utf-8", user), nil},
+		{"invalid request and response bodies", exchange("POST", base+"/users", "application/json", `{"email": "ann"}`, 201, "application/json", `{"email": "ann@example.com"}`), []string{
+			`request body: /: missing required property "password"`,
+			`request body: /email: "ann" is not a valid email`,
+			`response body: /: missing required property "id"`,
+		}},
+		{"missing request body", exchange("POST", base+"/users", "", "", 201, "", ""), []string{`request body: the request body is required`}},
+		{"wildcard media type is not validated", exchange("POST", base+"/users", "text/plain", "ann", 201, "", ""), nil},
+		{"undocumented media type", exchange("POST", base+"/users", "application/xml", "<user/>", 201, "", ""), []string{
+			`request body: application/xml is not documented (documented: application/json, text/*)`,
+		}},
+		{"invalid JSON", exchange("POST", base+"/users", "application/json", `{"email":`, 201, "", ""), []string{
+			`request body: invalid JSON: unexpected end of JSON input`,
+		}},
+		{"status range", exchange("POST", base+"/users", "text/plain", "ann", 422, "application/problem+json", `{}`), []string{
+			`response body: /: missing required property "title"`,
+		}},
+		{"undocumented status", exchange("POST", base+"/users", "text/plain", "ann", 500, "", ""), []string{
+			`status: status 500 is not documented for POST /users (documented: 201, 4XX)`,
+		}},
+		{"default response", exchange("GET", base+"/users/7", "", "", 404, "application/json", `{"id": "7", "email": "ann@example.com"}`), []string{
+			`response body: /id: expected integer, got string`,
+		}},
+		{"path without the server prefix", exchange("GET", "http://localhost:8080/users/me", "", "", 200, "", ""), nil},
+		{"undocumented method", exchange("DELETE", base+"/users/7", "", "", 204, "", ""), []string{
+			`operation: DELETE /users/{id} is not documented (the path has no DELETE operation)`,
+		}},
+		{"undocumented path", exchange("GET", base+"/orders", "", "", 200, "", ""), []string{`operation: GET /v1/orders is not documented`}},
+		{"invalid URL", exchange("GET", "http://[::1", "", "", 200, "", ""), []string{
+			`operation: invalid request URL "http://[::1": parse "http://[::1": missing ']' in host`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range doc.Check(tt.exchange) {
+				got = append(got, v.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	doc := loadFixture(t, "contract.yaml")
+	tests := []struct {
+		method   string
+		path     string
+		want     string
+		wantFind bool
+	}{
+		{"GET", "/users", "/users", true},
+		{"get", "/v1/users/", "/users", true},
+		{"GET", "/v1/users/7", "/users/{id}", true},
+		{"GET", "/v1/users/me", "/users/me", true},
+		{"GET", "/files/report.json", "/files/{name}.json", true},
+		{"GET", "/files/report.csv", "", false},
+		{"GET", "/users/7/orders", "", false},
+		{"PUT", "/users/7", "", false},
+	}
+	for _, tt := range tests {
+		op, ok := doc.Find(tt.method, tt.path)
+		if ok != tt.wantFind || op.Path != tt.want {
+			t.Errorf("Find(%q, %q) = %q, %t, want %q, %t", tt.method, tt.path, op.Path, ok, tt.want, tt.wantFind)
+		}
+	}
+}
@@ -0,0 +1,482 @@
+// Package openapi reads OpenAPI 3 documents, in YAML or JSON, into the operations they
+// describe, with every local reference ("$ref": "#/components/...") already resolved.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// methods lists the HTTP methods an OpenAPI path item can describe, in the order
+// operations are returned.
+var methods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+// Document is a parsed OpenAPI 3 document.
+type Document struct {
+	Version    string      // Value of the "openapi" field, e.g. "3.0.3".
+	Title      string      // Title of the API.
+	Servers    []string    // URLs of the servers, with their variables set to the default values.
+	Operations []Operation // Operations, sorted by path and then by method.
+	Unresolved []string    // References that could not be resolved (external files, typos...).
+
+	root     map[string]interface{}
+	prefixes []string // Paths of the server URLs, e.g. "/v1", which request paths may start with.
+}
+
+// Operation is a method of a path of the API.
+type Operation struct {
+	Method      string       // HTTP method, in upper case.
+	Path        string       // Path template, e.g. "/users/{id}".
+	ID          string       // operationId, if any.
+	Summary     string       // Summary, or the description when there is no summary.
+	Tags        []string     // Tags of the operation.
+	Parameters  []Parameter  // Path item and operation parameters; the latter override the former.
+	RequestBody *RequestBody // Request body, nil if the operation takes none.
+	Responses   []Response   // Documented responses, sorted by status code ("default" last).
+	Security    []Security   // First security requirement that applies, empty for public operations.
+
+	pattern *regexp.Regexp // Matches the request paths of the operation.
+}
+
+// Name returns a readable name for the operation: the summary, the operationId or
+// the method and path.
+func (o Operation) Name() string {
+	if o.Summary != "" {
+		return o.Summary
+	}
+	if o.ID != "" {
+		return o.ID
+	}
+	return o.Method + " " + o.Path
+}
+
+// Parameter is a path, query, header or cookie parameter.
+type Parameter struct {
+	Name     string      // Name of the parameter.
+	In       string      // Location: "path", "query", "header" or "cookie".
+	Required bool        // Whether the parameter must be sent (always true for path parameters).
+	Schema   interface{} // Schema of the value, nil if not given.
+	Example  interface{} // Example value, nil if not given.
+}
+
+// RequestBody is the body accepted by an operation.
+type RequestBody struct {
+	Required bool        // Whether the body must be sent.
+	Content  []MediaType // Accepted media types, in alphabetical order.
+}
+
+// Response is a documented response of an operation.
+type Response struct {
+	Code        string      // Status code, a range such as "2XX", or "default".
+	Description string      // Description of the response.
+	Content     []MediaType // Media types of the body, in alphabetical order.
+}
+
+// MediaType is the body of a request or response in a given format.
+type MediaType struct {
+	Type    string      // Media type, e.g. "application/json".
+	Schema  interface{} // Schema of the body, nil if not given.
+	Example interface{} // Example body (the first of "examples" if there is no "example"), nil if not given.
+}
+
+// Security is a security scheme required by an operation.
+type Security struct {
+	Name   string // Name of the scheme in components.securitySchemes.
+	Type   string // "http", "apiKey", "oauth2", "openIdConnect" or "mutualTLS".
+	Scheme string // HTTP authentication scheme, e.g. "bearer" or "basic" (type http).
+	In     string // Location of the API key: "header", "query" or "cookie" (type apiKey).
+	Param  string // Name of the header, query parameter or cookie with the API key (type apiKey).
+}
+
+// IsJSON reports whether a media type carries JSON, e.g. "application/json" or
+// "application/problem+json".
+func IsJSON(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(strings.ToLower(mediaType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// JSONContent returns the first JSON media type of a list, if any.
+func JSONContent(content []MediaType) (MediaType, bool) {
+	for _, m := range content {
+		if IsJSON(m.Type) {
+			return m, true
+		}
+	}
+	return MediaType{}, false
+}
+
+// Load reads an OpenAPI document from a YAML or JSON file.
+//
+// Parameters:
+//   - path (string): Path to the document.
+//
+// Returns:
+//   - *Document: The parsed document.
+//   - error: An error if the file cannot be read or is not an OpenAPI 3 document.
+func Load(path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening OpenAPI document: %v", err)
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse reads an OpenAPI 3 document in YAML or JSON (which is also valid YAML).
+//
+// Parameters:
+//   - r (io.Reader): The document.
+//
+// Returns:
+//   - *Document: The parsed document.
+//   - error: An error if the document is not valid YAML or not an OpenAPI 3 document.
+func Parse(r io.Reader) (*Document, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading OpenAPI document: %v", err)
+	}
+	var raw interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing OpenAPI document: %v", err)
+	}
+	// Go through JSON so values have the same types as decoded responses
+	// (float64 numbers, map[string]interface{} objects), whatever the source format.
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing OpenAPI document: %v", err)
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("error parsing OpenAPI document: not an object")
+	}
+
+	d := &Document{root: root}
+	d.Version, _ = root["openapi"].(string)
+	if !strings.HasPrefix(d.Version, "3.") {
+		if _, ok := root["swagger"]; ok {
+			return nil, fmt.Errorf("error parsing OpenAPI document: Swagger 2.0 is not supported, convert it to OpenAPI 3 first")
+		}
+		return nil, fmt.Errorf("error parsing OpenAPI document: missing or unsupported \"openapi\" version %q", d.Version)
+	}
+	info, _ := root["info"].(map[string]interface{})
+	d.Title, _ = info["title"].(string)
+	d.Servers = servers(root["servers"])
+	for _, server := range d.Servers {
+		if u, err := url.Parse(server); err == nil && strings.Trim(u.Path, "/") != "" {
+			d.prefixes = append(d.prefixes, strings.TrimSuffix(u.EscapedPath(), "/"))
+		}
+	}
+
+	paths, _ := root["paths"].(map[string]interface{})
+	for _, path := range sortedKeys(paths) {
+		item, _ := d.resolve(paths[path]).(map[string]interface{})
+		for _, method := range methods {
+			if op, ok := d.resolve(item[method]).(map[string]interface{}); ok {
+				d.Operations = append(d.Operations, d.operation(method, path, item, op))
+			}
+		}
+	}
+	return d, nil
+}
+
+// operation builds an Operation from its path item and operation objects.
+func (d *Document) operation(method, path string, item, op map[string]interface{}) Operation {
+	o := Operation{Method: strings.ToUpper(method), Path: path, pattern: templatePattern(path)}
+	o.ID, _ = op["operationId"].(string)
+	o.Summary, _ = op["summary"].(string)
+	if o.Summary == "" {
+		o.Summary, _ = op["description"].(string)
+		o.Summary, _, _ = strings.Cut(strings.TrimSpace(o.Summary), "\n")
+	}
+	for _, tag := range list(op["tags"]) {
+		if tag, ok := tag.(string); ok {
+			o.Tags = append(o.Tags, tag)
+		}
+	}
+
+	// Operation parameters override the path item ones with the same name and location
+	index := make(map[string]int)
+	for _, params := range []interface{}{item["parameters"], op["parameters"]} {
+		for _, raw := range list(params) {
+			p := d.parameter(raw)
+			if p.Name == "" {
+				continue
+			}
+			if i, ok := index[p.In+":"+p.Name]; ok {
+				o.Parameters[i] = p
+				continue
+			}
+			index[p.In+":"+p.Name] = len(o.Parameters)
+			o.Parameters = append(o.Parameters, p)
+		}
+	}
+
+	if body, ok := d.resolve(op["requestBody"]).(map[string]interface{}); ok {
+		o.RequestBody = &RequestBody{Required: body["required"] == true, Content: d.content(body["content"])}
+	}
+
+	responses, _ := op["responses"].(map[string]interface{})
+	for _, code := range sortedKeys(responses) {
+		response, _ := d.resolve(responses[code]).(map[string]interface{})
+		description, _ := response["description"].(string)
+		o.Responses = append(o.Responses, Response{Code: code, Description: description, Content: d.content(response["content"])})
+	}
+
+	requirements, ok := op["security"]
+	if !ok {
+		requirements = d.root["security"]
+	}
+	if first := list(requirements); len(first) > 0 {
+		names, _ := first[0].(map[string]interface{})
+		components, _ := d.root["components"].(map[string]interface{})
+		schemes, _ := components["securitySchemes"].(map[string]interface{})
+		for _, name := range sortedKeys(names) {
+			scheme, _ := d.resolve(schemes[name]).(map[string]interface{})
+			s := Security{Name: name}
+			s.Type, _ = scheme["type"].(string)
+			s.Scheme, _ = scheme["scheme"].(string)
+			s.Scheme = strings.ToLower(s.Scheme)
+			s.In, _ = scheme["in"].(string)
+			s.Param, _ = scheme["name"].(string)
+			o.Security = append(o.Security, s)
+		}
+	}
+	return o
+}
+
+// parameter builds a Parameter from a parameter object.
+func (d *Document) parameter(raw interface{}) Parameter {
+	param, _ := d.resolve(raw).(map[string]interface{})
+	p := Parameter{Required: param["required"] == true}
+	p.Name, _ = param["name"].(string)
+	p.In, _ = param["in"].(string)
+	if p.In == "path" {
+		p.Required = true
+	}
+	if s, ok := param["schema"]; ok {
+		p.Schema = d.Inline(s)
+	}
+	p.Example = d.example(param)
+	return p
+}
+
+// content builds the media types of a content map.
+func (d *Document) content(raw interface{}) []MediaType {
+	content, _ := d.resolve(raw).(map[string]interface{})
+	var media []MediaType
+	for _, name := range sortedKeys(content) {
+		m, _ := d.resolve(content[name]).(map[string]interface{})
+		mt := MediaType{Type: name, Example: d.example(m)}
+		if s, ok := m["schema"]; ok {
+			mt.Schema = d.Inline(s)
+		}
+		media = append(media, mt)
+	}
+	return media
+}
+
+// Inline returns a copy of a schema where every reference is replaced by the schema it
+// points to, so it can be validated on its own. A reference back to a schema that is
+// being inlined (a recursive schema) is replaced by an empty schema, which accepts any
+// value.
+//
+// Parameters:
+//   - s (interface{}): A schema of the document.
+//
+// Returns:
+//   - interface{}: The self-contained schema.
+func (d *Document) Inline(s interface{}) interface{} {
+	return d.inline(s, map[string]bool{})
+}
+
+// inline copies a node, replacing references; active holds the references being inlined.
+func (d *Document) inline(node interface{}, active map[string]bool) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := n["$ref"].(string); ok {
+			if active[ref] {
+				return map[string]interface{}{}
+			}
+			target, ok := d.lookup(ref)
+			if !ok {
+				return map[string]interface{}{}
+			}
+			active[ref] = true
+			defer delete(active, ref)
+			return d.inline(target, active)
+		}
+		copied := make(map[string]interface{}, len(n))
+		for key, value := range n {
+			copied[key] = d.inline(value, active)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(n))
+		for i, value := range n {
+			copied[i] = d.inline(value, active)
+		}
+		return copied
+	}
+	return node
+}
+
+// ForResponse adapts a schema to validate responses: properties marked writeOnly are
+// only sent by clients, so they are no longer required.
+//
+// Parameters:
+//   - s (interface{}): A schema without references, as returned by Inline.
+//
+// Returns:
+//   - interface{}: The adapted copy of the schema.
+func ForResponse(s interface{}) interface{} {
+	return withoutRequired(s, "writeOnly")
+}
+
+// ForRequest adapts a schema to validate requests: properties marked readOnly are
+// only sent by servers, so they are no longer required.
+//
+// Parameters:
+//   - s (interface{}): A schema without references, as returned by Inline.
+//
+// Returns:
+//   - interface{}: The adapted copy of the schema.
+func ForRequest(s interface{}) interface{} {
+	return withoutRequired(s, "readOnly")
+}
+
+// withoutRequired copies a schema, removing from the required lists the properties
+// whose schema sets the given flag (readOnly or writeOnly).
+func withoutRequired(node interface{}, flag string) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(n))
+		for key, value := range n {
+			copied[key] = withoutRequired(value, flag)
+		}
+		properties, _ := n["properties"].(map[string]interface{})
+		if required, ok := n["required"].([]interface{}); ok && properties != nil {
+			kept := []interface{}{}
+			for _, name := range required {
+				property, _ := properties[fmt.Sprint(name)].(map[string]interface{})
+				if property[flag] != true {
+					kept = append(kept, name)
+				}
+			}
+			copied["required"] = kept
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(n))
+		for i, value := range n {
+			copied[i] = withoutRequired(value, flag)
+		}
+		return copied
+	}
+	return node
+}
+
+// resolve follows the reference of a node, if it is one, up to the referenced node.
+func (d *Document) resolve(node interface{}) interface{} {
+	for i := 0; i < 32; i++ { // Bounded, in case references point to each other
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return node
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return node
+		}
+		if node, ok = d.lookup(ref); !ok {
+			return nil
+		}
+	}
+	return nil
+}
+
+// lookup returns the node a local reference such as "#/components/schemas/User"
+// points to, and records the references it cannot resolve.
+func (d *Document) lookup(ref string) (interface{}, bool) {
+	if pointer, ok := strings.CutPrefix(ref, "#/"); ok {
+		var node interface{} = d.root
+		found := true
+		for _, segment := range strings.Split(pointer, "/") {
+			obj, _ := node.(map[string]interface{})
+			if node, found = obj[strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)]; !found {
+				break
+			}
+		}
+		if found {
+			return node, true
+		}
+	}
+	for _, unresolved := range d.Unresolved {
+		if unresolved == ref {
+			return nil, false
+		}
+	}
+	d.Unresolved = append(d.Unresolved, ref)
+	return nil, false
+}
+
+// servers returns the URLs of the servers, replacing their variables by the default values.
+func servers(raw interface{}) []string {
+	var urls []string
+	for _, s := range list(raw) {
+		server, _ := s.(map[string]interface{})
+		u, _ := server["url"].(string)
+		if u == "" {
+			continue
+		}
+		variables, _ := server["variables"].(map[string]interface{})
+		for name, v := range variables {
+			variable, _ := v.(map[string]interface{})
+			if value, ok := variable["default"].(string); ok {
+				u = strings.ReplaceAll(u, "{"+name+"}", value)
+			}
+		}
+		urls = append(urls, strings.TrimSuffix(u, "/"))
+	}
+	return urls
+}
+
+// example returns the "example" of an object, else the value of the first of its
+// "examples", else nil.
+func (d *Document) example(obj map[string]interface{}) interface{} {
+	if value, ok := obj["example"]; ok {
+		return value
+	}
+	examples, _ := obj["examples"].(map[string]interface{})
+	for _, name := range sortedKeys(examples) {
+		if ex, ok := d.resolve(examples[name]).(map[string]interface{}); ok {
+			if value, ok := ex["value"]; ok {
+				return value
+			}
+		}
+	}
+	return nil
+}
+
+// list returns a node as a list, or nil if it is not one.
+func list(node interface{}) []interface{} {
+	l, _ := node.([]interface{})
+	return l
+}
+
+// sortedKeys returns the keys of an object in alphabetical order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
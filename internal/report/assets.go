@@ -0,0 +1,100 @@
+package report
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// AssetMode decides how the CSS and JavaScript libraries used by the report are included.
+type AssetMode string
+
+const (
+	AssetsCDN    AssetMode = "cdn"    // Link the libraries from their CDNs (small file, needs Internet access to view).
+	AssetsInline AssetMode = "inline" // Embed the vendored libraries in the HTML file, so it works offline.
+)
+
+// embeddedAssets holds the libraries embedded in inline mode. They are downloaded
+// into assets/vendor by scripts/fetch-report-assets.sh from the URLs listed in
+// libraries, so both modes always use the same versions, and checked against the
+// SHA-256 pinned there, so a tampered CDN response is never embedded.
+//
+//go:embed assets
+var embeddedAssets embed.FS
+
+// vendoredAssets is where inline mode reads the libraries from (replaced in tests).
+var vendoredAssets fs.FS = embeddedAssets
+
+// library is a CSS or JavaScript file used by the report.
+type library struct {
+	url    string // CDN location, used in CDN mode.
+	file   string // Name of the vendored copy under assets/vendor, used in inline mode.
+	sha256 string // Hex SHA-256 of the vendored copy; empty until it has been reviewed and pinned.
+}
+
+// libraries lists the files used by the report, in the order they must be loaded.
+// scripts/fetch-report-assets.sh reads the entries from this file: keep one per line.
+var libraries = []library{
+	{"https://cdn.jsdelivr.net/npm/bootstrap@5.3.1/dist/css/bootstrap.min.css", "bootstrap.min.css", ""},
+	{"https://cdn.datatables.net/1.13.6/css/dataTables.bootstrap5.min.css", "dataTables.bootstrap5.min.css", ""},
+	{"https://cdn.jsdelivr.net/npm/chart.js@4.4.0/dist/chart.umd.js", "chart.umd.js", ""},
+	{"https://code.jquery.com/jquery-3.7.1.min.js", "jquery-3.7.1.min.js", ""},
+	{"https://cdn.datatables.net/1.13.6/js/jquery.dataTables.min.js", "jquery.dataTables.min.js", ""},
+	{"https://cdn.datatables.net/1.13.6/js/dataTables.bootstrap5.min.js", "dataTables.bootstrap5.min.js", ""},
+	{"https://cdn.jsdelivr.net/npm/xlsx@0.18.5/dist/xlsx.full.min.js", "xlsx.full.min.js", ""},
+}
+
+// assetTags returns the <link>/<script> tags (CDN mode) or the <style>/<script>
+// elements with the vendored contents (inline mode) to place in the report head.
+// Inline elements are labelled with the file name, so the report references no CDN.
+//
+// Parameters:
+//   - mode (AssetMode): How the libraries are included.
+//
+// Returns:
+//   - template.HTML: The markup for the head of the report.
+//   - error: An error in inline mode if a library has not been vendored, or its
+//     contents do not match the SHA-256 pinned in libraries.
+func assetTags(mode AssetMode) (template.HTML, error) {
+	var b strings.Builder
+	var missing, unverified []string
+	for _, lib := range libraries {
+		css := strings.HasSuffix(lib.file, ".css")
+		if mode != AssetsInline {
+			if css {
+				fmt.Fprintf(&b, "<link href=\"%s\" rel=\"stylesheet\">\n", lib.url)
+			} else {
+				fmt.Fprintf(&b, "<script src=\"%s\"></script>\n", lib.url)
+			}
+			continue
+		}
+
+		content, err := fs.ReadFile(vendoredAssets, path.Join("assets", "vendor", lib.file))
+		if err != nil {
+			missing = append(missing, lib.file)
+			continue
+		}
+		if sum := sha256.Sum256(content); lib.sha256 == "" || hex.EncodeToString(sum[:]) != lib.sha256 {
+			unverified = append(unverified, lib.file)
+			continue
+		}
+		if css {
+			fmt.Fprintf(&b, "<style>/* %s */\n%s\n</style>\n", lib.file, strings.ReplaceAll(string(content), "</style", `<\/style`))
+		} else {
+			// A literal "</script" inside the library would close the element early
+			fmt.Fprintf(&b, "<script>/* %s */\n%s\n</script>\n", lib.file, strings.ReplaceAll(string(content), "</script", `<\/script`))
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("report assets not vendored: %s (run scripts/fetch-report-assets.sh and rebuild)", strings.Join(missing, ", "))
+	}
+	if len(unverified) > 0 {
+		return "", fmt.Errorf("report assets do not match their pinned SHA-256: %s (fetch them again with scripts/fetch-report-assets.sh and rebuild)", strings.Join(unverified, ", "))
+	}
+	return template.HTML(strings.TrimSuffix(b.String(), "\n")), nil
+}
@@ -0,0 +1,152 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// renderReport renders an empty report with the given options and returns the HTML.
+func renderReport(t *testing.T, opts Options) (string, error) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "report.html")
+	if err := GenerateUltimateReport(ReportData{}, file, opts); err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content), nil
+}
+
+// checkNoCDN fails the test if the report links or names any library CDN.
+func checkNoCDN(t *testing.T, html string) {
+	t.Helper()
+	for _, lib := range libraries {
+		if strings.Contains(html, lib.url) {
+			t.Errorf("inline report references %s", lib.url)
+		}
+	}
+	for _, link := range []string{`src="http`, `href="http`, "cdn.jsdelivr.net", "cdn.datatables.net", "code.jquery.com"} {
+		if strings.Contains(html, link) {
+			t.Errorf("inline report contains %q", link)
+		}
+	}
+}
+
+// standInAssets replaces the vendored libraries with small stand-ins, pinning their
+// SHA-256, until the test ends. The stand-ins end with the closing tag of the element
+// holding them, which must not end it early.
+func standInAssets(t *testing.T) fstest.MapFS {
+	t.Helper()
+	savedAssets, savedLibraries := vendoredAssets, libraries
+	t.Cleanup(func() { vendoredAssets, libraries = savedAssets, savedLibraries })
+
+	vendored := fstest.MapFS{}
+	libraries = append([]library(nil), libraries...)
+	for i, lib := range libraries {
+		closing := "</script>"
+		if strings.HasSuffix(lib.file, ".css") {
+			closing = "</style>"
+		}
+		content := []byte("/* " + lib.file + " content */ " + closing)
+		sum := sha256.Sum256(content)
+		libraries[i].sha256 = hex.EncodeToString(sum[:])
+		vendored[path.Join("assets", "vendor", lib.file)] = &fstest.MapFile{Data: content}
+	}
+	vendoredAssets = vendored
+	return vendored
+}
+
+func TestReportAssetsInline(t *testing.T) {
+	standInAssets(t)
+
+	html, err := renderReport(t, Options{Assets: AssetsInline})
+	if err != nil {
+		t.Fatalf("GenerateUltimateReport() error = %v", err)
+	}
+	checkNoCDN(t, html)
+	for _, lib := range libraries {
+		if !strings.Contains(html, "/* "+lib.file+" content */") {
+			t.Errorf("inline report does not embed %s", lib.file)
+		}
+	}
+	if strings.Contains(html, "content */ </script>") || strings.Contains(html, "content */ </style>") {
+		t.Error("inline report does not escape closing tags inside the libraries")
+	}
+}
+
+func TestReportAssetsTampered(t *testing.T) {
+	vendored := standInAssets(t)
+	vendored["assets/vendor/chart.umd.js"].Data = []byte("/* chart.umd.js content */ alert(document.cookie)")
+
+	file := filepath.Join(t.TempDir(), "report.html")
+	err := GenerateUltimateReport(ReportData{}, file, Options{Assets: AssetsInline})
+	if err == nil || !strings.Contains(err.Error(), "pinned SHA-256: chart.umd.js (") {
+		t.Errorf("GenerateUltimateReport() error = %v, want chart.umd.js reported as not matching its pin", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("a report was written with a tampered library")
+	}
+}
+
+// TestLibrariesPinFormat checks that every library is declared on a line that
+// scripts/fetch-report-assets.sh can read, with a well-formed SHA-256 when pinned.
+func TestLibrariesPinFormat(t *testing.T) {
+	source, err := os.ReadFile("assets.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := regexp.MustCompile(`\{"https://[^"]+", "[^"]+", "([0-9a-f]{64})?"\}`).FindAllString(string(source), -1)
+	if len(declared) != len(libraries) {
+		t.Errorf("the script would read %d libraries from assets.go, want %d:\n%s", len(declared), len(libraries), strings.Join(declared, "\n"))
+	}
+}
+
+func TestReportAssetsCDN(t *testing.T) {
+	html, err := renderReport(t, Options{})
+	if err != nil {
+		t.Fatalf("GenerateUltimateReport() error = %v", err)
+	}
+	for _, lib := range libraries {
+		if !strings.Contains(html, `"`+lib.url+`"`) {
+			t.Errorf("CDN report does not link %s", lib.url)
+		}
+	}
+}
+
+func TestReportAssetsMissing(t *testing.T) {
+	defer func(saved fs.FS) { vendoredAssets = saved }(vendoredAssets)
+	vendoredAssets = fstest.MapFS{"assets/vendor/chart.umd.js": &fstest.MapFile{Data: []byte("chart")}}
+
+	file := filepath.Join(t.TempDir(), "report.html")
+	err := GenerateUltimateReport(ReportData{}, file, Options{Assets: AssetsInline})
+	if err == nil || !strings.Contains(err.Error(), "bootstrap.min.css") || strings.Contains(err.Error(), "chart.umd.js") {
+		t.Errorf("GenerateUltimateReport() error = %v, want the missing libraries listed", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("a report was written despite the missing libraries")
+	}
+}
+
+// TestReportAssetsVendored checks the libraries actually embedded in the binary.
+func TestReportAssetsVendored(t *testing.T) {
+	for _, lib := range libraries {
+		if _, err := fs.Stat(embeddedAssets, path.Join("assets", "vendor", lib.file)); err != nil {
+			t.Skipf("%s is not vendored; run scripts/fetch-report-assets.sh", lib.file)
+		}
+	}
+	html, err := renderReport(t, Options{Assets: AssetsInline})
+	if err != nil {
+		t.Fatalf("GenerateUltimateReport() error = %v", err)
+	}
+	checkNoCDN(t, html)
+}
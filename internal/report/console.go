@@ -0,0 +1,192 @@
+package report
+
+import (
+	"fmt"
+	"go-api-testing/internal/test"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ANSI escape sequences used by the interactive console output.
+const (
+	ansiReset     = "\033[0m"
+	ansiRed       = "\033[31m"
+	ansiGreen     = "\033[32m"
+	ansiYellow    = "\033[33m"
+	ansiGray      = "\033[90m"
+	ansiBold      = "\033[1m"
+	ansiClearLine = "\r\033[K"
+)
+
+// progressBarWidth is the number of characters of the progress bar.
+const progressBarWidth = 30
+
+// progressInterval is how often the progress line is redrawn while no test finishes.
+var progressInterval = time.Second
+
+// ConsoleReporter prints one line per test as soon as it finishes, followed by a
+// summary once the run is over. On a terminal the status is colored and a progress
+// bar with an estimate of the remaining time stays below the results; otherwise
+// (e.g. in CI logs) each line is plain text with a "[done/total]" counter.
+type ConsoleReporter struct {
+	w           io.Writer
+	total       int
+	interactive bool // Redraw a progress line below the results.
+	color       bool // Color the statuses.
+	start       time.Time
+
+	mu                                   sync.Mutex
+	done, passed, failed, blocked, skips int
+	stop                                 chan struct{}
+	stopped                              sync.WaitGroup
+}
+
+// NewConsoleReporter returns a reporter for a run of total tests. The interactive
+// mode is used when w is a terminal and plain is false; colors are also disabled
+// when the NO_COLOR environment variable is set.
+//
+// Parameters:
+//   - w (io.Writer): Destination of the output, usually os.Stdout.
+//   - total (int): Number of tests of the run, skipped tests included.
+//   - plain (bool): Force the plain mode, even on a terminal.
+//
+// Returns:
+//   - *ConsoleReporter: The reporter, already showing the progress line in interactive mode.
+func NewConsoleReporter(w io.Writer, total int, plain bool) *ConsoleReporter {
+	interactive := false
+	if f, ok := w.(*os.File); ok && !plain {
+		interactive = isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+	}
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return newConsoleReporter(w, total, interactive, interactive && !noColor)
+}
+
+// newConsoleReporter returns a reporter with the given modes, starting the goroutine
+// that redraws the progress line in interactive mode.
+func newConsoleReporter(w io.Writer, total int, interactive, color bool) *ConsoleReporter {
+	c := &ConsoleReporter{w: w, total: total, interactive: interactive, color: color, start: time.Now()}
+	if c.interactive {
+		// Keep the elapsed time moving while a slow test runs, so the run does not look hung
+		c.stop = make(chan struct{})
+		c.stopped.Add(1)
+		go func() {
+			defer c.stopped.Done()
+			ticker := time.NewTicker(progressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					c.mu.Lock()
+					c.drawProgress()
+					c.mu.Unlock()
+				case <-c.stop:
+					return
+				}
+			}
+		}()
+		c.drawProgress()
+	}
+	return c
+}
+
+// WriteResult prints the line of a finished test and updates the progress.
+//
+// Parameters:
+//   - r (test.Result): The result of the test.
+func (c *ConsoleReporter) WriteResult(r test.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.done++
+	var label, color string
+	switch r.Status {
+	case test.StatusPassed:
+		c.passed++
+		label, color = "PASS", ansiGreen
+	case test.StatusBlocked:
+		c.blocked++
+		label, color = "BLOCK", ansiYellow
+	case test.StatusSkipped:
+		c.skips++
+		label, color = "SKIP", ansiGray
+	default:
+		c.failed++
+		label, color = "FAIL", ansiRed
+	}
+
+	line := fmt.Sprintf("%s %s %s", c.paint(color+ansiBold, fmt.Sprintf("%-5s", label)), r.TestCase.TestId, r.TestCase.TestCase)
+	if r.Status == test.StatusPassed || r.Status == test.StatusFailed {
+		line += c.paint(ansiGray, fmt.Sprintf(" (%s)", r.Duration.Round(time.Millisecond)))
+	}
+	if r.Status != test.StatusPassed && r.Status != test.StatusSkipped {
+		line += " " + r.Summary()
+	}
+
+	if c.interactive {
+		fmt.Fprint(c.w, ansiClearLine+line+"\n")
+		c.drawProgress()
+	} else {
+		width := len(fmt.Sprint(c.total))
+		fmt.Fprintf(c.w, "[%*d/%d] %s\n", width, c.done, c.total, line)
+	}
+}
+
+// Finish removes the progress line and prints the summary of the run.
+//
+// Parameters:
+//   - runID (int64): Id of the run, shown in the summary.
+func (c *ConsoleReporter) Finish(runID int64) {
+	if c.stop != nil {
+		close(c.stop)
+		c.stopped.Wait()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.interactive {
+		fmt.Fprint(c.w, ansiClearLine)
+	}
+	elapsed := time.Since(c.start).Round(time.Millisecond)
+	fmt.Fprintf(c.w, "\nRun %d: %s, %s, %s", runID,
+		c.paint(ansiGreen, fmt.Sprintf("%d passed", c.passed)),
+		c.paint(ansiRed, fmt.Sprintf("%d failed", c.failed)),
+		c.paint(ansiYellow, fmt.Sprintf("%d blocked", c.blocked)))
+	if c.skips > 0 {
+		fmt.Fprintf(c.w, ", %s", c.paint(ansiGray, fmt.Sprintf("%d skipped", c.skips)))
+	}
+	fmt.Fprintf(c.w, " in %s\n", elapsed)
+}
+
+// drawProgress redraws the progress line: a bar, the counter, the elapsed time and
+// the estimated time left, extrapolated from the average time per finished test.
+// The caller must hold c.mu.
+func (c *ConsoleReporter) drawProgress() {
+	if !c.interactive || c.total == 0 {
+		return
+	}
+	filled := progressBarWidth * c.done / c.total
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	elapsed := time.Since(c.start)
+	eta := "ETA --"
+	if c.done > 0 && c.done < c.total {
+		left := elapsed / time.Duration(c.done) * time.Duration(c.total-c.done)
+		eta = "ETA " + left.Round(time.Second).String()
+	} else if c.done == c.total {
+		eta = "done"
+	}
+	fmt.Fprintf(c.w, "%s%s %d/%d (%d%%) · %s · %s", ansiClearLine, c.paint(ansiGreen, bar),
+		c.done, c.total, 100*c.done/c.total, elapsed.Round(time.Second), eta)
+}
+
+// paint wraps text in the given ANSI color when colors are enabled.
+func (c *ConsoleReporter) paint(color, text string) string {
+	if !c.color {
+		return text
+	}
+	return color + text + ansiReset
+}
@@ -0,0 +1,142 @@
+package report
+
+import (
+	"bytes"
+	"go-api-testing/internal/test"
+	"go-api-testing/models"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// elapsedPattern matches the elapsed time at the end of the summary, which changes on every run.
+var elapsedPattern = regexp.MustCompile(` in [0-9.]+[µnm]?s\n$`)
+
+// consoleResults returns one result of each status.
+func consoleResults() []test.Result {
+	return []test.Result{
+		{TestCase: models.TestCase{TestId: "TC-001", TestCase: "List users"}, Status: test.StatusPassed, Duration: 42 * time.Millisecond},
+		{TestCase: models.TestCase{TestId: "TC-002", TestCase: "Create user"}, Status: test.StatusFailed, Message: "Error in request: connection refused", Duration: 1500 * time.Millisecond},
+		{TestCase: models.TestCase{TestId: "TC-003", TestCase: "Delete user"}, Status: test.StatusBlocked, Message: "Blocked by TC-002"},
+		{TestCase: models.TestCase{TestId: "TC-004", TestCase: "Legacy"}, Status: test.StatusSkipped, Message: "Run=N"},
+	}
+}
+
+func TestConsoleReporterPlain(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		results []test.Result
+		want    string
+	}{
+		{
+			name:    "all statuses",
+			total:   4,
+			results: consoleResults(),
+			want: "[1/4] PASS  TC-001 List users (42ms)\n" +
+				"[2/4] FAIL  TC-002 Create user (1.5s) Error in request: connection refused\n" +
+				"[3/4] BLOCK TC-003 Delete user Blocked by TC-002\n" +
+				"[4/4] SKIP  TC-004 Legacy\n" +
+				"\nRun 7: 1 passed, 1 failed, 1 blocked, 1 skipped",
+		},
+		{
+			name:    "counter padded to the total and no skipped tests",
+			total:   10,
+			results: consoleResults()[:1],
+			want: "[ 1/10] PASS  TC-001 List users (42ms)\n" +
+				"\nRun 7: 1 passed, 0 failed, 0 blocked",
+		},
+		{
+			name:  "empty run",
+			total: 0,
+			want:  "\nRun 7: 0 passed, 0 failed, 0 blocked",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			// A buffer is not a terminal: the reporter must fall back to the plain mode
+			c := NewConsoleReporter(&b, tt.total, false)
+			for _, r := range tt.results {
+				c.WriteResult(r)
+			}
+			c.Finish(7)
+
+			got := b.String()
+			if !elapsedPattern.MatchString(got) {
+				t.Fatalf("output does not end with the elapsed time:\n%q", got)
+			}
+			if got = elapsedPattern.ReplaceAllString(got, ""); got != tt.want {
+				t.Errorf("output mismatch\ngot:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleReporterInteractive(t *testing.T) {
+	defer func(interval time.Duration) { progressInterval = interval }(progressInterval)
+	progressInterval = time.Millisecond
+
+	var b bytes.Buffer
+	c := newConsoleReporter(&b, 4, true, false)
+	// Let the goroutine redraw the progress line a few times while no test finishes
+	time.Sleep(20 * time.Millisecond)
+
+	// Results come from several workers at once, racing with the redraws
+	var wg sync.WaitGroup
+	for _, r := range consoleResults() {
+		wg.Add(1)
+		go func(r test.Result) {
+			defer wg.Done()
+			c.WriteResult(r)
+		}(r)
+	}
+	wg.Wait()
+	c.Finish(7)
+
+	got := b.String()
+	if n := strings.Count(got, "0/4 (0%) · 0s · ETA --"); n < 2 {
+		t.Errorf("progress line redrawn %d time(s) before the first result, want at least 2:\n%q", n, got)
+	}
+	for _, want := range []string{
+		ansiClearLine + "PASS  TC-001 List users (42ms)\n",
+		ansiClearLine + "BLOCK TC-003 Delete user Blocked by TC-002\n",
+		"4/4 (100%) · 0s · done",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%q", want, got)
+		}
+	}
+	if strings.Contains(got, "\033[3") {
+		t.Errorf("output is colored although colors are disabled:\n%q", got)
+	}
+
+	// Finish clears the progress line and stops the goroutine: nothing is drawn after the summary
+	summary := strings.LastIndex(got, ansiClearLine+"\nRun 7: 1 passed, 1 failed, 1 blocked, 1 skipped in ")
+	if summary < 0 || !elapsedPattern.MatchString(got[summary:]) {
+		t.Fatalf("output does not end with the summary:\n%q", got)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if b.String() != got {
+		t.Errorf("output written after Finish:\n%q", strings.TrimPrefix(b.String(), got))
+	}
+}
+
+func TestConsoleReporterColor(t *testing.T) {
+	var b bytes.Buffer
+	c := newConsoleReporter(&b, 1, true, true)
+	c.WriteResult(consoleResults()[1])
+	c.Finish(7)
+
+	got := b.String()
+	for _, want := range []string{
+		ansiRed + ansiBold + "FAIL " + ansiReset + " TC-002 Create user" + ansiGray + " (1.5s)" + ansiReset,
+		"Run 7: " + ansiGreen + "0 passed" + ansiReset + ", " + ansiRed + "1 failed" + ansiReset + ", " + ansiYellow + "0 blocked" + ansiReset + " in ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%q", want, got)
+		}
+	}
+}
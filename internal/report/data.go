@@ -0,0 +1,44 @@
+package report
+
+import (
+	"go-api-testing/internal/analysis"
+	"go-api-testing/internal/db"
+	"go-api-testing/internal/test"
+	"time"
+)
+
+// ReportData is the data model passed to the report templates, both the embedded
+// default and custom ones. Templates reach the fields with the usual dot notation,
+// e.g. {{.Run.Environment}} or {{range .Results}}{{.TestCase.TestId}}{{end}}.
+type ReportData struct {
+	// GeneratedAt is the time the report was generated.
+	GeneratedAt time.Time
+	// Run is the run that produced Results: its id, start and end time, environment,
+	// suite file and hash, git commit, host, seed and totals.
+	Run db.Run
+	// Results holds the executed tests of the current run, in suite order. Each one has
+	// the TestCase, its Status ("passed", "failed" or "blocked"), Message, Duration,
+	// the response Diff and, for executed tests, the sanitized request/response Exchange.
+	Results []test.Result
+	// Runs lists the recent runs included in the report, newest first.
+	Runs []db.Run
+	// History holds the saved results of Runs, newest first.
+	History []db.HistoryEntry
+	// Flaky lists the tests flagged as flaky over recent runs, most flaky first.
+	Flaky []analysis.Stability
+	// Latency holds the latency trend of each test over Runs, with the tests that regressed
+	// against their rolling baseline, and the latency percentiles of each endpoint.
+	Latency analysis.LatencyAnalysis
+	// Comparison describes the changes since the previous run of the same suite and
+	// environment, or is nil if there is no such run.
+	Comparison *analysis.RunComparison
+}
+
+// Options tunes how the report is rendered, as opposed to what it shows.
+type Options struct {
+	// Assets decides how the CSS/JS libraries are included (CDN links by default).
+	Assets AssetMode
+	// Templates lists custom template files. They may redefine blocks of the default
+	// layout or provide a complete page; see GenerateUltimateReport.
+	Templates []string
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api-testing/internal/api"
+	"go-api-testing/internal/test"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// templateFuncs returns the helpers available to the report templates, the default
+// and custom ones alike:
+//
+//   - assets: the <link>/<script> tags (or inline contents) of the CSS/JS libraries.
+//   - passCount, failCount, blockedCount: number of results passed, not passed and blocked.
+//   - generalStatus: overall verdict of the results ("✔️ Excellent", "⚠️ Warning" or "❌ Fail").
+//   - statusLabel: display name of a test.Status ("Passed", "Failed"...).
+//   - shortCommit: git commit abbreviated to 10 characters.
+//   - formatTime: a time as "2006-01-02 15:04:05" in local time.
+//   - formatDuration: a duration rounded to milliseconds.
+//   - percent: a ratio between 0 and 1 as a percentage, e.g. "75%".
+//   - latencyPercent: a relative change multiplied by 100.
+//   - headerLines: HTTP headers as "Name: value" lines, sorted by name.
+//   - statusText: reason phrase of an HTTP status code.
+//   - curl: an api.Request as a curl command, with its credentials masked.
+//   - upper, lower: upper- and lower-case a string.
+//   - toJSON: a value as indented JSON text.
+//   - marshal: a value as JSON, safe to assign to a JavaScript variable.
+//
+// Parameters:
+//   - assets (template.HTML): The markup returned by the assets helper.
+//
+// Returns:
+//   - template.FuncMap: The helpers, by name.
+func templateFuncs(assets template.HTML) template.FuncMap {
+	return template.FuncMap{
+		"assets": func() template.HTML { return assets },
+		"passCount": func(results []test.Result) int {
+			count := 0
+			for _, r := range results {
+				if r.Passed() {
+					count++
+				}
+			}
+			return count
+		},
+		"failCount": func(results []test.Result) int {
+			count := 0
+			for _, r := range results {
+				if !r.Passed() {
+					count++
+				}
+			}
+			return count
+		},
+		"blockedCount": func(results []test.Result) int {
+			count := 0
+			for _, r := range results {
+				if r.Status == test.StatusBlocked {
+					count++
+				}
+			}
+			return count
+		},
+		"generalStatus": func(results []test.Result) string {
+			total := len(results)
+			fails := 0
+			for _, r := range results {
+				if !r.Passed() {
+					fails++
+				}
+			}
+			ratio := float64(fails) / float64(total)
+			switch {
+			case ratio == 0:
+				return "✔️ Excellent"
+			case ratio < 0.2:
+				return "⚠️ Warning"
+			default:
+				return "❌ Fail"
+			}
+		},
+		"statusLabel": func(status test.Status) string {
+			switch status {
+			case test.StatusPassed:
+				return "Passed"
+			case test.StatusBlocked:
+				return "Blocked"
+			case test.StatusSkipped:
+				return "Skipped"
+			default:
+				return "Failed"
+			}
+		},
+		"shortCommit": shortCommit,
+		"formatTime": func(t time.Time) string {
+			return t.Local().Format("2006-01-02 15:04:05")
+		},
+		"formatDuration": func(d time.Duration) string {
+			return d.Round(time.Millisecond).String()
+		},
+		"percent": func(ratio float64) string {
+			return fmt.Sprintf("%.0f%%", ratio*100)
+		},
+		"latencyPercent": func(change float64) float64 {
+			return change * 100
+		},
+		"headerLines": func(headers http.Header) string {
+			names := make([]string, 0, len(headers))
+			for name := range headers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			var b strings.Builder
+			for _, name := range names {
+				for _, value := range headers[name] {
+					fmt.Fprintf(&b, "%s: %s\n", name, value)
+				}
+			}
+			return b.String()
+		},
+		"statusText": http.StatusText,
+		"curl": func(req api.Request) string {
+			return api.Curl(req, true)
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"toJSON": func(v interface{}) string {
+			b, _ := json.MarshalIndent(v, "", "  ")
+			return string(b)
+		},
+		"marshal": func(v interface{}) template.JS {
+			b, _ := json.Marshal(v)
+			return template.JS(b)
+		},
+	}
+}
@@ -1,25 +1,42 @@
 package report
 
 import (
-	"encoding/json"
+	_ "embed" // Embeds the default report template
 	"fmt"
 	"html/template"
 	"os"
+	"strings"
+	"text/template/parse"
 )
 
-type ReportData struct {
-	Results   [][]string
-	Historico []map[string]interface{}
-}
-
-func GenerateUltimateReport(results [][]string, historico []map[string]interface{}, filePath string) error {
-	if len(results) > 0 {
-		results = results[1:] // remove header
+// defaultTemplate is the layout used unless a custom template replaces it.
+//
+//go:embed templates/dashboard.html.tmpl
+var defaultTemplate string
+
+// GenerateUltimateReport renders the HTML report into filePath.
+// The embedded default layout is always loaded first. Custom template files are then
+// parsed into the same set, in order: a file made only of {{define}} blocks overrides
+// those blocks of the default layout (e.g. "header", "footer" or "head"), while the
+// first file with content of its own outside {{define}} replaces the whole page and
+// can still include the default blocks with {{template "name" .}}.
+//
+// Parameters:
+//   - data (ReportData): The data passed to the templates.
+//   - filePath (string): Path of the HTML file to write.
+//   - opts (Options): How to include the libraries and which custom templates to use.
+//
+// Returns:
+//   - error: An error if an asset or template cannot be loaded, or rendering fails.
+func GenerateUltimateReport(data ReportData, filePath string, opts Options) error {
+	// Resolve the libraries and templates first, so a failure does not leave an empty report behind
+	assets, err := assetTags(opts.Assets)
+	if err != nil {
+		return err
 	}
-
-	data := ReportData{
-		Results:   results,
-		Historico: historico,
+	t, err := loadTemplates(assets, opts.Templates)
+	if err != nil {
+		return err
 	}
 
 	file, err := os.Create(filePath)
@@ -28,181 +45,50 @@ func GenerateUltimateReport(results [][]string, historico []map[string]interface
 	}
 	defer file.Close()
 
-	tmpl := `
-<!DOCTYPE html>
-<html lang="en">
-<head>
-<meta charset="UTF-8">
-<title>API Test Dashboard</title>
-<link href="https://cdn.jsdelivr.net/npm/bootstrap@5.3.1/dist/css/bootstrap.min.css" rel="stylesheet">
-<link href="https://cdn.datatables.net/1.13.6/css/dataTables.bootstrap5.min.css" rel="stylesheet">
-<script src="https://cdn.jsdelivr.net/npm/chart.js"></script>
-<script src="https://code.jquery.com/jquery-3.7.1.min.js"></script>
-<script src="https://cdn.datatables.net/1.13.6/js/jquery.dataTables.min.js"></script>
-<script src="https://cdn.datatables.net/1.13.6/js/dataTables.bootstrap5.min.js"></script>
-<script src="https://cdn.jsdelivr.net/npm/xlsx/dist/xlsx.full.min.js"></script>
-<style>
-body { font-family: "Segoe UI", Tahoma, Geneva, Verdana, sans-serif; background:#f8f9fa; color:#343a40; }
-h1,h2{ text-align:center; margin:20px 0; }
-.card { margin-bottom:15px; }
-.table td, .table th { vertical-align: middle; }
-.status-pass { color:#28a745; font-weight:bold; }
-.status-fail { color:#dc3545; font-weight:bold; }
-.message-cell { max-width:350px; overflow:hidden; text-overflow:ellipsis; white-space:nowrap; }
-.message-cell.expanded { white-space:normal; }
-.expand-btn { cursor:pointer; color:#0d6efd; text-decoration:underline; font-size:12px; }
-.filter-container { display:flex; justify-content:center; gap:10px; flex-wrap:wrap; margin-bottom:20px; }
-</style>
-</head>
-<body>
-<div class="container">
-<h1>📊 API Test Dashboard</h1>
-
-<!-- KPIs -->
-<div class="row text-center">
-  <div class="col-md-3"><div class="card"><div class="card-body"><h5>Total Tests</h5><p class="fs-3">{{len .Results}}</p></div></div></div>
-  <div class="col-md-3"><div class="card"><div class="card-body"><h5>Passed</h5><p class="fs-3">{{passCount .Results}}</p></div></div></div>
-  <div class="col-md-3"><div class="card"><div class="card-body"><h5>Failed</h5><p class="fs-3">{{failCount .Results}}</p></div></div></div>
-  <div class="col-md-3"><div class="card"><div class="card-body"><h5>Overall Status</h5><p class="fs-3">{{generalStatus .Results}}</p></div></div></div>
-</div>
-
-<!-- Filters -->
-<div class="filter-container">
-  <select id="filterTestCase" class="form-select w-auto"><option value="">All TestCases</option></select>
-  <select id="filterResult" class="form-select w-auto"><option value="">All Results</option><option value="true">Passed</option><option value="false">Failed</option></select>
-  <button class="btn btn-primary" onclick="exportTableToExcel('resultsTable','API_Test_Report')">Export Excel</button>
-</div>
-
-<!-- Charts -->
-<div class="row">
-  <div class="col-md-6"><h3 class="text-center">Tests per TestCase</h3><canvas id="barChart" height="200"></canvas></div>
-  <div class="col-md-6"><h3 class="text-center">Result Distribution</h3><canvas id="pieChart" height="200"></canvas></div>
-</div>
-
-<!-- Results Table -->
-<div class="table-responsive mt-4">
-<table id="resultsTable" class="table table-striped table-bordered table-hover">
-<thead class="table-dark"><tr><th>TestId</th><th>TestCase</th><th>Result</th><th>Message</th></tr></thead>
-<tbody>
-{{range $index, $row := .Results}}
-<tr>
-<td>{{index $row 0}}</td>
-<td>{{index $row 1}}</td>
-<td class="{{if eq (index $row 2) "true"}}status-pass{{else}}status-fail{{end}}">{{if eq (index $row 2) "true"}}Passed{{else}}Failed{{end}}</td>
-<td><div id="msg-{{$index}}" class="message-cell">{{index $row 3}}</div>{{if gt (len (index $row 3)) 50}} <span class="expand-btn" onclick="toggleMessage('msg-{{$index}}', this)">See More</span>{{end}}</td>
-</tr>
-{{end}}
-</tbody>
-</table>
-</div>
-</div>
-
-<script>
-function toggleMessage(id, btn){
-  const cell = document.getElementById(id);
-  cell.classList.toggle('expanded');
-  btn.innerText = cell.classList.contains('expanded') ? 'See Less' : 'See More';
-}
-function exportTableToExcel(tableID, filename=''){
-  var table = document.getElementById(tableID);
-  var wb = XLSX.utils.table_to_book(table,{sheet:"Sheet1"});
-  XLSX.writeFile(wb, filename+'.xlsx');
+	return t.Execute(file, data)
 }
 
-$(document).ready(function(){
-  var table = $('#resultsTable').DataTable({pageLength:10});
-  var testCaseSet = new Set();
-  table.column(1).data().each(function(value){ testCaseSet.add(value); });
-  testCaseSet.forEach(function(tc){ $('#filterTestCase').append('<option value="'+tc+'">'+tc+'</option>'); });
-  $('#filterTestCase,#filterResult').on('change', function(){
-    var tc = $('#filterTestCase').val();
-    var res = $('#filterResult').val();
-    table.rows().every(function(){
-      var show=true;
-      if(tc && this.data()[1]!=tc) show=false;
-      if(res && this.data()[2]!=res) show=false;
-      $(this.node()).toggle(show);
-    });
-  });
-});
-
-// Chart Data
-const historico = {{marshal .Historico}};
-let grouped={}, totalPassed=0, totalFailed=0;
-historico.forEach(h=>{
-  const tc = h.test_case || h.TestCase;
-  if(!grouped[tc]) grouped[tc]={passed:0, failed:0};
-  if(h.result || h.Result){ grouped[tc].passed++; totalPassed++; }else{ grouped[tc].failed++; totalFailed++; }
-});
-const labels = Object.keys(grouped);
-const passedData = labels.map(l=>grouped[l].passed);
-const failedData = labels.map(l=>grouped[l].failed);
-
-// Bar Chart
-new Chart(document.getElementById('barChart').getContext('2d'),{
-  type:'bar',
-  data:{labels:labels,datasets:[{label:'Passed',data:passedData,backgroundColor:'#28a745'},{label:'Failed',data:failedData,backgroundColor:'#dc3545'}]},
-  options:{responsive:true,plugins:{legend:{position:'top'}},scales:{y:{beginAtZero:true,stepSize:1}}}
-});
-
-// Pie Chart
-new Chart(document.getElementById('pieChart').getContext('2d'),{
-  type:'pie',
-  data:{labels:['Passed','Failed'],datasets:[{data:[totalPassed,totalFailed],backgroundColor:['#28a745','#dc3545']}]},
-  options:{responsive:true,plugins:{legend:{position:'top'}}}
-});
-</script>
-</body>
-</html>
-`
-
-	funcMap := template.FuncMap{
-		"passCount": func(results [][]string) int {
-			count := 0
-			for _, r := range results {
-				if r[2] == "true" {
-					count++
-				}
-			}
-			return count
-		},
-		"failCount": func(results [][]string) int {
-			count := 0
-			for _, r := range results {
-				if r[2] != "true" {
-					count++
-				}
-			}
-			return count
-		},
-		"generalStatus": func(results [][]string) string {
-			total := len(results)
-			fails := 0
-			for _, r := range results {
-				if r[2] != "true" {
-					fails++
-				}
-			}
-			ratio := float64(fails) / float64(total)
-			switch {
-			case ratio == 0:
-				return "✔️ Excellent"
-			case ratio < 0.2:
-				return "⚠️ Warning"
-			default:
-				return "❌ Fail"
-			}
-		},
-		"marshal": func(v interface{}) template.JS {
-			b, _ := json.Marshal(v)
-			return template.JS(b)
-		},
+// loadTemplates parses the default layout and the custom template files, and returns
+// the template to execute.
+func loadTemplates(assets template.HTML, files []string) (*template.Template, error) {
+	root, err := template.New("report").Funcs(templateFuncs(assets)).Parse(defaultTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("template parse error: %v", err)
 	}
 
-	t, err := template.New("report").Funcs(funcMap).Parse(tmpl)
-	if err != nil {
-		return fmt.Errorf("template parse error: %v", err)
+	entry := root
+	for i, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading report template: %v", err)
+		}
+		// Named by position and path: files with the same base name must not replace each other
+		custom, err := root.New(fmt.Sprintf("%d:%s", i+1, path)).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("template parse error in %s: %v", path, err)
+		}
+		if entry == root && hasContent(custom) {
+			entry = custom
+		}
 	}
+	return entry, nil
+}
 
-	return t.Execute(file, data)
+// hasContent reports whether a template renders anything outside its {{define}} blocks.
+func hasContent(t *template.Template) bool {
+	if t.Tree == nil || t.Tree.Root == nil {
+		return false
+	}
+	for _, node := range t.Tree.Root.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			if strings.TrimSpace(string(n.Text)) != "" {
+				return true
+			}
+		case *parse.CommentNode:
+		default:
+			return true
+		}
+	}
+	return false
 }
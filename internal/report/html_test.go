@@ -0,0 +1,110 @@
+package report
+
+import (
+	"go-api-testing/internal/api"
+	"go-api-testing/internal/test"
+	"go-api-testing/models"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCustomTemplates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	footer := write("footer.tmpl", `{{define "footer"}}<footer>CUSTOM FOOTER</footer>{{end}}`)
+	page := write("page.tmpl", `<p>CUSTOM PAGE</p>{{template "footer" .}}`)
+	otherPage := write("other/page.tmpl", `<p>OTHER PAGE</p>`)
+	samePageName := write("a/custom.tmpl", `<p>PAGE A</p>{{template "footer" .}}`)
+	sameFooterName := write("b/custom.tmpl", `{{define "footer"}}FOOTER B{{end}}`)
+	invalid := write("invalid.tmpl", `{{if}}`)
+
+	tests := []struct {
+		name    string
+		files   []string
+		want    []string // Fragments the report must contain.
+		notWant []string // Fragments the report must not contain.
+		wantErr string
+	}{
+		{"default layout", nil, []string{"<!DOCTYPE html>"}, []string{"CUSTOM"}, ""},
+		{"block override", []string{footer}, []string{"<!DOCTYPE html>", "CUSTOM FOOTER"}, nil, ""},
+		{"full page with blocks", []string{page, footer}, []string{"CUSTOM PAGE", "CUSTOM FOOTER"}, []string{"<!DOCTYPE html>"}, ""},
+		{"first full page wins", []string{page, otherPage}, []string{"CUSTOM PAGE"}, []string{"OTHER PAGE"}, ""},
+		{"same base name, page first", []string{samePageName, sameFooterName}, []string{"PAGE A", "FOOTER B"}, nil, ""},
+		{"same base name, blocks first", []string{sameFooterName, samePageName}, []string{"PAGE A", "FOOTER B"}, nil, ""},
+		{"same base name, two pages", []string{page, otherPage, samePageName}, []string{"CUSTOM PAGE"}, []string{"PAGE A", "OTHER PAGE"}, ""},
+		{"parse error", []string{invalid}, nil, nil, "template parse error in " + invalid},
+		{"missing file", []string{filepath.Join(dir, "missing.tmpl")}, nil, nil, "error reading report template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := renderReport(t, Options{Templates: tt.files})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GenerateUltimateReport() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateUltimateReport() error = %v", err)
+			}
+			for _, fragment := range tt.want {
+				if !strings.Contains(html, fragment) {
+					t.Errorf("report does not contain %q", fragment)
+				}
+			}
+			for _, fragment := range tt.notWant {
+				if strings.Contains(html, fragment) {
+					t.Errorf("report contains %q", fragment)
+				}
+			}
+		})
+	}
+}
+
+func TestReportCurl(t *testing.T) {
+	// Exchanges are sanitized before they reach the report, as in main
+	exchange := api.Exchange{
+		Request:  api.Request{Method: "POST", URL: "https://api.example.com/users", Headers: http.Header{"Authorization": {"Bearer secret-token"}}, Body: `{"name":"Ann"}`},
+		Response: api.Response{StatusCode: 500},
+	}.Sanitized(1024)
+	data := ReportData{Results: []test.Result{
+		{TestCase: models.TestCase{TestId: "OK"}, Status: test.StatusPassed, Exchange: &exchange},
+		{TestCase: models.TestCase{TestId: "KO"}, Status: test.StatusFailed, Exchange: &exchange},
+		{TestCase: models.TestCase{TestId: "SKIPPED"}, Status: test.StatusBlocked},
+	}}
+	file := filepath.Join(t.TempDir(), "report.html")
+	if err := GenerateUltimateReport(data, file, Options{}); err != nil {
+		t.Fatalf("GenerateUltimateReport() error = %v", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+
+	if got := strings.Count(html, ">Show curl</span>"); got != 1 {
+		t.Errorf("report has %d curl buttons, want 1 (the failed test)", got)
+	}
+	if !strings.Contains(html, `id="curl-cmd-1"`) || strings.Contains(html, `id="curl-cmd-0"`) {
+		t.Errorf("curl command is not shown for the failed test only")
+	}
+	if strings.Contains(html, "secret-token") {
+		t.Errorf("report contains the unmasked token")
+	}
+	if !strings.Contains(html, "main curl KO") {
+		t.Errorf("report does not explain how to get the unmasked command")
+	}
+}
@@ -0,0 +1,226 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api-testing/internal/test"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	maxMarkdownFailures = 50  // Failures listed in the table; the rest are only counted.
+	maxMarkdownDetails  = 20  // Failures whose diff or full message is included.
+	maxMarkdownMessage  = 120 // Characters of a message shown in the failures table.
+	maxMarkdownDiff     = 30  // Differences shown per failure.
+	maxMarkdownValue    = 80  // Characters of a JSON value shown in a diff.
+)
+
+// GenerateMarkdownReport writes a Markdown summary of the run into filePath, meant for
+// pull request comments and CI job summaries (e.g. $GITHUB_STEP_SUMMARY): the totals,
+// a table of the failed and blocked tests, collapsed <details> blocks with their diffs,
+// and a link to the HTML report.
+//
+// Parameters:
+//   - data (ReportData): The data of the run, as passed to the HTML report.
+//   - filePath (string): Path of the Markdown file to write.
+//   - htmlURL (string): Link to the full HTML report, e.g. the CI artifact. If empty, the
+//     HTML report file is linked relative to the Markdown file.
+//   - htmlFile (string): Path of the HTML report file.
+//   - appendToFile (bool): Whether to add the summary after the current contents of the
+//     file, as required by $GITHUB_STEP_SUMMARY, instead of replacing them.
+//
+// Returns:
+//   - error: An error if the file cannot be written.
+func GenerateMarkdownReport(data ReportData, filePath, htmlURL, htmlFile string, appendToFile bool) error {
+	if htmlURL == "" && htmlFile != "" {
+		htmlURL = htmlFile
+		if rel, err := filepath.Rel(filepath.Dir(filePath), htmlFile); err == nil {
+			htmlURL = filepath.ToSlash(rel)
+		}
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendToFile {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(filePath, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening Markdown file: %v", err)
+	}
+	defer file.Close()
+
+	content := RenderMarkdown(data, htmlURL)
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		content = "\n" + content // Keep the summary apart from the previous contents
+	}
+	if _, err := file.WriteString(content); err != nil {
+		return fmt.Errorf("error writing Markdown file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing Markdown file: %v", err)
+	}
+	return nil
+}
+
+// RenderMarkdown returns the Markdown summary of the run written by GenerateMarkdownReport.
+//
+// Parameters:
+//   - data (ReportData): The data of the run.
+//   - htmlURL (string): Link to the full HTML report; no link is added if empty.
+//
+// Returns:
+//   - string: The Markdown document.
+func RenderMarkdown(data ReportData, htmlURL string) string {
+	var b strings.Builder
+	var failures []test.Result
+	passed, failed, blocked := 0, 0, 0
+	for _, r := range data.Results {
+		switch r.Status {
+		case test.StatusPassed:
+			passed++
+			continue
+		case test.StatusBlocked:
+			blocked++
+		default:
+			failed++
+		}
+		failures = append(failures, r)
+	}
+
+	// Headline and run details
+	if len(failures) == 0 {
+		fmt.Fprintf(&b, "## ✅ API tests: all %d passed\n\n", len(data.Results))
+	} else {
+		fmt.Fprintf(&b, "## ❌ API tests: %d failed, %d blocked of %d\n\n", failed, blocked, len(data.Results))
+	}
+	details := []string{fmt.Sprintf("Run #%d", data.Run.ID)}
+	if data.Run.Environment != "" {
+		details = append(details, "environment `"+data.Run.Environment+"`")
+	}
+	if data.Run.GitCommit != "" {
+		details = append(details, "commit `"+shortCommit(data.Run.GitCommit)+"`")
+	}
+	if d := data.Run.Duration(); d > 0 {
+		details = append(details, "took "+d.Round(time.Millisecond).String())
+	}
+	b.WriteString(strings.Join(details, " · ") + "\n\n")
+
+	b.WriteString("| Total | ✅ Passed | ❌ Failed | ⏭️ Blocked |\n|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d |\n\n", len(data.Results), passed, failed, blocked)
+
+	if c := data.Comparison; c != nil {
+		fmt.Fprintf(&b, "Since run #%d: %d newly failed, %d newly passed, %d slower, %d added, %d removed.\n\n",
+			c.Base.ID, len(c.NewlyFailed), len(c.NewlyPassed), len(c.Slower), len(c.Added), len(c.Removed))
+	}
+
+	// Failures table, then the details of each failure
+	if len(failures) > 0 {
+		b.WriteString("### Failures\n\n| TestId | TestCase | Status | Message |\n|---|---|---|---|\n")
+		for i, r := range failures {
+			if i == maxMarkdownFailures {
+				fmt.Fprintf(&b, "\n… and %d more.\n", len(failures)-maxMarkdownFailures)
+				break
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", tableCell(r.TestCase.TestId), tableCell(r.TestCase.TestCase),
+				r.Status, tableCell(truncate(r.Summary(), maxMarkdownMessage)))
+		}
+		b.WriteString("\n")
+
+		shown := 0
+		for _, r := range failures {
+			body := failureDetails(r)
+			if body == "" {
+				continue
+			}
+			if shown == maxMarkdownDetails {
+				b.WriteString("More failures are detailed in the HTML report.\n\n")
+				break
+			}
+			shown++
+			fmt.Fprintf(&b, "<details><summary><code>%s</code> %s</summary>\n\n%s\n</details>\n\n",
+				htmlEscape(r.TestCase.TestId), htmlEscape(r.TestCase.TestCase), body)
+		}
+	}
+
+	if htmlURL != "" {
+		fmt.Fprintf(&b, "📄 [Full HTML report](%s)\n", htmlURL)
+	}
+	return b.String()
+}
+
+// failureDetails returns the fenced block shown in the <details> of a failure: its
+// differences as a diff, or its full message when the table only shows part of it.
+func failureDetails(r test.Result) string {
+	if len(r.Diff) > 0 {
+		var lines []string
+		for i, d := range r.Diff {
+			if i == maxMarkdownDiff {
+				lines = append(lines, fmt.Sprintf("# ... and %d more", len(r.Diff)-maxMarkdownDiff))
+				break
+			}
+			path := d.Path
+			if path == "" {
+				path = "/"
+			}
+			// Changed values become a removed and an added line, so they are highlighted
+			if d.Kind != test.DiffAdded {
+				lines = append(lines, fmt.Sprintf("- %s: %s", path, markdownJSON(d.Old)))
+			}
+			if d.Kind != test.DiffRemoved {
+				lines = append(lines, fmt.Sprintf("+ %s: %s", path, markdownJSON(d.New)))
+			}
+		}
+		return fence("diff", strings.Join(lines, "\n"))
+	}
+	if strings.Contains(r.Message, "\n") || len(r.Message) > maxMarkdownMessage {
+		return fence("text", r.Message)
+	}
+	return ""
+}
+
+// fence wraps text in a fenced code block, using a fence longer than any backtick run in it.
+func fence(lang, text string) string {
+	ticks := "```"
+	for strings.Contains(text, ticks) {
+		ticks += "`"
+	}
+	return ticks + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + ticks + "\n"
+}
+
+// markdownJSON encodes a value as single-line JSON, shortening long values.
+func markdownJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return truncate(string(b), maxMarkdownValue)
+}
+
+// tableCell escapes text for a Markdown table cell, which must fit in one line.
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "\r", "")
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return htmlEscape(s)
+}
+
+// htmlEscape escapes the characters that GitHub would otherwise interpret as HTML.
+func htmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// truncate shortens s to at most max characters, marking the cut with an ellipsis.
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// shortCommit abbreviates a git commit to 10 characters.
+func shortCommit(commit string) string {
+	if len(commit) > 10 {
+		return commit[:10]
+	}
+	return commit
+}
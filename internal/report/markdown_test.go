@@ -0,0 +1,85 @@
+package report
+
+import (
+	"go-api-testing/internal/db"
+	"go-api-testing/internal/test"
+	"go-api-testing/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMarkdownReport(t *testing.T) {
+	data := ReportData{
+		Run: db.Run{ID: 7},
+		Results: []test.Result{
+			{TestCase: models.TestCase{TestId: "A", TestCase: "List"}, Status: test.StatusPassed},
+			{TestCase: models.TestCase{TestId: "B", TestCase: "Create | user"}, Status: test.StatusFailed, Message: "Expected 201, got 500"},
+		},
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "summary.md")
+	const previous = "## Build\n\nAll good.\n"
+
+	tests := []struct {
+		name         string
+		appendToFile bool
+		wantPrevious bool
+	}{
+		{"replace", false, false},
+		{"append", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(file, []byte(previous), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := GenerateMarkdownReport(data, file, "", filepath.Join(dir, "html", "report.html"), tt.appendToFile); err != nil {
+				t.Fatalf("GenerateMarkdownReport() error = %v", err)
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(content)
+			if strings.HasPrefix(got, previous) != tt.wantPrevious {
+				t.Errorf("previous contents kept = %t, want %t:\n%s", !tt.wantPrevious, tt.wantPrevious, got)
+			}
+			if tt.wantPrevious && !strings.HasPrefix(got, previous+"\n## ") {
+				t.Errorf("appended summary is not separated from the previous contents:\n%s", got)
+			}
+			for _, fragment := range []string{"## ❌ API tests: 1 failed, 0 blocked of 2", "Run #7", `| B | Create \| user | failed | Expected 201, got 500 |`, "(html/report.html)"} {
+				if !strings.Contains(got, fragment) {
+					t.Errorf("summary does not contain %q:\n%s", fragment, got)
+				}
+			}
+		})
+	}
+
+	missing := filepath.Join(dir, "new.md")
+	if err := GenerateMarkdownReport(data, missing, "https://ci.test/run/1", "", true); err != nil {
+		t.Fatalf("GenerateMarkdownReport(new file) error = %v", err)
+	}
+	if content, _ := os.ReadFile(missing); !strings.HasPrefix(string(content), "## ") || !strings.Contains(string(content), "(https://ci.test/run/1)") {
+		t.Errorf("summary appended to a new file =\n%s", content)
+	}
+}
+
+func TestMarkdownTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"truncated", 5, "trun…"},
+		{"ñandú añejo", 6, "ñandú…"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.max); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api-testing/internal/test"
+	"io"
+	"strings"
+)
+
+// TAPWriter streams results in the Test Anything Protocol, version 14
+// (https://testanything.org/tap-version-14-specification.html), one test point
+// per result as soon as it is written.
+type TAPWriter struct {
+	w     io.Writer
+	count int // Test points written so far.
+	err   error
+}
+
+// NewTAPWriter writes the TAP version line and the plan, and returns a writer for
+// the test points.
+//
+// Parameters:
+//   - w (io.Writer): Destination of the stream, e.g. os.Stdout.
+//   - total (int): Number of test points that will be written, skipped tests included.
+//
+// Returns:
+//   - *TAPWriter: The writer.
+func NewTAPWriter(w io.Writer, total int) *TAPWriter {
+	t := &TAPWriter{w: w}
+	t.printf("TAP version 14\n1..%d\n", total)
+	return t
+}
+
+// WriteResult writes the test point of a result: "ok" or "not ok" with the TestId and
+// TestCase as description, "# SKIP" for tests disabled with Run=N or blocked by a
+// dependency, and a YAML diagnostic block with the message, the expected and actual
+// HTTP status, the duration and the OpenAPI contract violations of executed tests.
+//
+// Parameters:
+//   - r (test.Result): The result of a finished test.
+func (t *TAPWriter) WriteResult(r test.Result) {
+	t.count++
+	tc := r.TestCase
+	description := tapEscape(strings.TrimSpace(tc.TestId + " " + tc.TestCase))
+
+	switch r.Status {
+	case test.StatusSkipped:
+		t.printf("ok %d - %s # SKIP Run=%s\n", t.count, description, tapEscape(tc.Run))
+		return
+	case test.StatusBlocked:
+		t.printf("ok %d - %s # SKIP %s\n", t.count, description, tapEscape(firstLine(r.Message)))
+		return
+	case test.StatusPassed:
+		t.printf("ok %d - %s\n", t.count, description)
+	default:
+		t.printf("not ok %d - %s\n", t.count, description)
+	}
+
+	t.printf("  ---\n")
+	if !r.Passed() {
+		t.printf("  message: %s\n", yamlString(r.Message, "    "))
+	}
+	t.printf("  status: %s\n", r.Status)
+	t.printf("  request: %s\n", yamlString(strings.TrimSpace(tc.Method+" "+tc.Endpoint), "    "))
+	t.printf("  expected_status: %d\n", tc.ExpectedStatusCode)
+	if r.Exchange != nil && r.Exchange.Response.StatusCode != 0 {
+		t.printf("  actual_status: %d\n", r.Exchange.Response.StatusCode)
+	} else {
+		t.printf("  actual_status: null\n")
+	}
+	t.printf("  duration_ms: %d\n", r.Duration.Milliseconds())
+	if len(r.Contract) > 0 {
+		t.printf("  contract_violations:\n")
+		for _, v := range r.Contract {
+			t.printf("    - %s\n", yamlString(v.String(), "      "))
+		}
+	}
+	t.printf("  ...\n")
+}
+
+// Err returns the first error found writing the stream, if any.
+func (t *TAPWriter) Err() error {
+	return t.err
+}
+
+// printf writes to the stream, remembering the first error so the run is not interrupted.
+func (t *TAPWriter) printf(format string, args ...interface{}) {
+	if t.err != nil {
+		return
+	}
+	if _, err := fmt.Fprintf(t.w, format, args...); err != nil {
+		t.err = fmt.Errorf("error writing TAP output: %v", err)
+	}
+}
+
+// tapEscape escapes the characters with a meaning in a TAP description or directive,
+// and keeps it on one line.
+func tapEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "#", `\#`, "\r", "", "\n", " ").Replace(s)
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// yamlString formats s as a YAML scalar: a literal block for multi-line text, indented
+// with indent, or a double-quoted string otherwise.
+func yamlString(s, indent string) string {
+	if strings.Contains(strings.TrimRight(s, "\n"), "\n") {
+		lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+		return "|-\n" + indent + strings.Join(lines, "\n"+indent)
+	}
+	b, _ := json.Marshal(s) // JSON strings are valid YAML double-quoted scalars
+	return string(b)
+}
@@ -0,0 +1,140 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"go-api-testing/internal/api"
+	"go-api-testing/internal/openapi"
+	"go-api-testing/internal/test"
+	"go-api-testing/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTAPWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewTAPWriter(&b, 5)
+	w.WriteResult(test.Result{
+		TestCase: models.TestCase{TestId: "TC-001", TestCase: "List users", Method: "GET", Endpoint: "/users", ExpectedStatusCode: 200},
+		Status:   test.StatusPassed,
+		Duration: 42 * time.Millisecond,
+		Exchange: &api.Exchange{Response: api.Response{StatusCode: 200}},
+	})
+	w.WriteResult(test.Result{
+		TestCase: models.TestCase{TestId: "TC-002", TestCase: `Create #1 with C:\path`, Method: "POST", Endpoint: "/users", ExpectedStatusCode: 201},
+		Status:   test.StatusFailed,
+		Message:  "Response does not match:\n/name: expected \"Ann\", got \"Bob\"\n",
+		Duration: 1500 * time.Millisecond,
+		Exchange: &api.Exchange{Response: api.Response{StatusCode: 500}},
+		Contract: []openapi.Violation{{Rule: openapi.RuleStatus, Message: "status 500 is not documented"}},
+	})
+	w.WriteResult(test.Result{
+		TestCase: models.TestCase{TestId: "TC-003", TestCase: "Timeout", Method: "GET", Endpoint: "/slow", ExpectedStatusCode: 200},
+		Status:   test.StatusFailed,
+		Message:  `Error in request: context deadline exceeded`,
+		Exchange: &api.Exchange{},
+	})
+	w.WriteResult(test.Result{
+		TestCase: models.TestCase{TestId: "TC-004", TestCase: "Delete user"},
+		Status:   test.StatusBlocked,
+		Message:  "Blocked by TC-002 # failed\nsecond line",
+	})
+	w.WriteResult(test.Result{TestCase: models.TestCase{TestId: "TC-005", Run: "N"}, Status: test.StatusSkipped})
+	if err := w.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+
+	want := `TAP version 14
+1..5
+ok 1 - TC-001 List users
+  ---
+  status: passed
+  request: "GET /users"
+  expected_status: 200
+  actual_status: 200
+  duration_ms: 42
+  ...
+not ok 2 - TC-002 Create \#1 with C:\\path
+  ---
+  message: |-
+    Response does not match:
+    /name: expected "Ann", got "Bob"
+  status: failed
+  request: "POST /users"
+  expected_status: 201
+  actual_status: 500
+  duration_ms: 1500
+  contract_violations:
+    - "status: status 500 is not documented"
+  ...
+not ok 3 - TC-003 Timeout
+  ---
+  message: "Error in request: context deadline exceeded"
+  status: failed
+  request: "GET /slow"
+  expected_status: 200
+  actual_status: null
+  duration_ms: 0
+  ...
+ok 4 - TC-004 Delete user # SKIP Blocked by TC-002 \# failed
+ok 5 - TC-005 # SKIP Run=N
+`
+	if got := b.String(); got != want {
+		t.Errorf("TAP stream =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{ writes int }
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	f.writes++
+	return 0, errors.New("broken pipe")
+}
+
+func TestTAPWriterError(t *testing.T) {
+	f := &failingWriter{}
+	w := NewTAPWriter(f, 1)
+	w.WriteResult(test.Result{TestCase: models.TestCase{TestId: "TC-001"}, Status: test.StatusPassed})
+	if err := w.Err(); err == nil || !strings.Contains(err.Error(), "broken pipe") {
+		t.Errorf("Err() = %v, want the write error", err)
+	}
+	if f.writes != 1 {
+		t.Errorf("%d writes after the first error, want none", f.writes-1)
+	}
+}
+
+func TestYAMLString(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"plain", `"plain"`},
+		{`quote " and \ backslash`, `"quote \" and \\ backslash"`},
+		{"trailing newline\n", `"trailing newline\n"`},
+		{"two\nlines\n", "|-\n  two\n  lines"},
+		{"", `""`},
+	}
+	for _, tt := range tests {
+		if got := yamlString(tt.s, "  "); got != tt.want {
+			t.Errorf("yamlString(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTAPEscape(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{`# not a directive \ here`, `\# not a directive \\ here`},
+		{"one\r\ntwo", "one two"},
+	}
+	for _, tt := range tests {
+		if got := tapEscape(tt.s); got != tt.want {
+			t.Errorf("tapEscape(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
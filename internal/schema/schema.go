@@ -0,0 +1,392 @@
+// Package schema validates JSON documents against JSON Schemas, in the dialect used by
+// OpenAPI 3: the usual validation keywords, "nullable" (3.0) and lists of types (3.1).
+// References ("$ref") are not followed, so schemas must be self-contained.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Violation is a value of the document that does not satisfy its schema.
+type Violation struct {
+	Path    string `json:"path"`    // JSON pointer to the value (empty for the whole document).
+	Message string `json:"message"` // Rule that is not satisfied.
+}
+
+// String returns the violation as "pointer: message".
+func (v Violation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + v.Message
+}
+
+// Load reads the schema referenced by a test case's ResponseSchema column: an inline
+// JSON object, or the path of a .json file containing it.
+//
+// Parameters:
+//   - source (string): Inline JSON schema or path to the schema file.
+//
+// Returns:
+//   - interface{}: The decoded schema.
+//   - error: An error if the source cannot be read or is not a JSON schema.
+func Load(source string) (interface{}, error) {
+	content := []byte(strings.TrimSpace(source))
+	if !strings.HasPrefix(string(content), "{") {
+		var err error
+		if content, err = os.ReadFile(string(content)); err != nil {
+			return nil, fmt.Errorf("error reading schema file: %v", err)
+		}
+	}
+	var s interface{}
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, fmt.Errorf("error parsing schema: %v", err)
+	}
+	if _, ok := s.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("error parsing schema: not a JSON object")
+	}
+	return s, nil
+}
+
+// Validate checks a decoded JSON value against a schema and lists every violation
+// found, in document order. A rule broken in several subschemas (e.g. a property
+// declared by two schemas of an allOf) is reported once. Unknown keywords and formats
+// are ignored.
+//
+// Parameters:
+//   - s (interface{}): The decoded schema, an object or a boolean.
+//   - value (interface{}): The decoded JSON value, as produced by encoding/json.
+//
+// Returns:
+//   - []Violation: The violations found; empty when the value is valid.
+func Validate(s interface{}, value interface{}) []Violation {
+	var violations []Violation
+	validate(nil, s, value, &violations)
+	seen := make(map[Violation]bool, len(violations))
+	unique := violations[:0]
+	for _, v := range violations {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// validate checks the value located at path and appends its violations to out.
+func validate(path []string, s interface{}, value interface{}, out *[]Violation) {
+	add := func(format string, args ...interface{}) {
+		*out = append(*out, Violation{Path: pointer(path), Message: fmt.Sprintf(format, args...)})
+	}
+
+	var rules map[string]interface{}
+	switch s := s.(type) {
+	case bool:
+		if !s {
+			add("no value is allowed here")
+		}
+		return
+	case map[string]interface{}:
+		rules = s
+	default:
+		return
+	}
+
+	if value == nil && rules["nullable"] == true {
+		return
+	}
+	if types := typeList(rules["type"]); len(types) > 0 && !hasType(types, value) {
+		add("expected %s, got %s", strings.Join(types, " or "), typeOf(value))
+		return
+	}
+	if enum, ok := rules["enum"].([]interface{}); ok && !contains(enum, value) {
+		add("%s is not one of %s", compactJSON(value), compactJSON(enum))
+	}
+	if c, ok := rules["const"]; ok && !equal(c, value) {
+		add("expected %s, got %s", compactJSON(c), compactJSON(value))
+	}
+
+	switch v := value.(type) {
+	case string:
+		validateString(rules, v, add)
+	case float64:
+		validateNumber(rules, v, add)
+	case []interface{}:
+		if n, ok := number(rules["minItems"]); ok && float64(len(v)) < n {
+			add("expected at least %v items, got %d", n, len(v))
+		}
+		if n, ok := number(rules["maxItems"]); ok && float64(len(v)) > n {
+			add("expected at most %v items, got %d", n, len(v))
+		}
+		if rules["uniqueItems"] == true {
+			for i := range v {
+				for j := i + 1; j < len(v); j++ {
+					if equal(v[i], v[j]) {
+						add("items %d and %d are equal", i, j)
+					}
+				}
+			}
+		}
+		if items, ok := rules["items"]; ok {
+			for i, item := range v {
+				validate(append(path, fmt.Sprint(i)), items, item, out)
+			}
+		}
+	case map[string]interface{}:
+		validateObject(path, rules, v, out, add)
+	}
+
+	if all, ok := rules["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			validate(path, sub, value, out)
+		}
+	}
+	if anyOf, ok := rules["anyOf"].([]interface{}); ok && countMatches(anyOf, value) == 0 {
+		add("does not match any of the %d allowed schemas", len(anyOf))
+	}
+	if one, ok := rules["oneOf"].([]interface{}); ok {
+		if n := countMatches(one, value); n != 1 {
+			add("matches %d of the %d schemas instead of exactly one", n, len(one))
+		}
+	}
+	if not, ok := rules["not"]; ok && len(Validate(not, value)) == 0 {
+		add("must not match the schema in \"not\"")
+	}
+}
+
+// validateString checks the string keywords.
+func validateString(rules map[string]interface{}, v string, add func(string, ...interface{})) {
+	length := utf8.RuneCountInString(v)
+	if n, ok := number(rules["minLength"]); ok && float64(length) < n {
+		add("expected at least %v characters, got %d", n, length)
+	}
+	if n, ok := number(rules["maxLength"]); ok && float64(length) > n {
+		add("expected at most %v characters, got %d", n, length)
+	}
+	if pattern, ok := rules["pattern"].(string); ok {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+			add("%q does not match the pattern %q", v, pattern)
+		}
+	}
+	if format, ok := rules["format"].(string); ok && !validFormat(format, v) {
+		add("%q is not a valid %s", v, format)
+	}
+}
+
+// validateNumber checks the numeric keywords. exclusiveMinimum and exclusiveMaximum
+// are booleans in OpenAPI 3.0 and numbers in 3.1; both forms are accepted.
+func validateNumber(rules map[string]interface{}, v float64, add func(string, ...interface{})) {
+	if n, ok := number(rules["minimum"]); ok {
+		if rules["exclusiveMinimum"] == true && v <= n {
+			add("expected a value greater than %v, got %v", n, v)
+		} else if v < n {
+			add("expected a value of at least %v, got %v", n, v)
+		}
+	}
+	if n, ok := number(rules["maximum"]); ok {
+		if rules["exclusiveMaximum"] == true && v >= n {
+			add("expected a value less than %v, got %v", n, v)
+		} else if v > n {
+			add("expected a value of at most %v, got %v", n, v)
+		}
+	}
+	if n, ok := number(rules["exclusiveMinimum"]); ok && v <= n {
+		add("expected a value greater than %v, got %v", n, v)
+	}
+	if n, ok := number(rules["exclusiveMaximum"]); ok && v >= n {
+		add("expected a value less than %v, got %v", n, v)
+	}
+	if n, ok := number(rules["multipleOf"]); ok && n > 0 {
+		if q := v / n; math.Abs(q-math.Round(q)) > 1e-9 {
+			add("%v is not a multiple of %v", v, n)
+		}
+	}
+}
+
+// validateObject checks the object keywords and validates the properties.
+func validateObject(path []string, rules map[string]interface{}, v map[string]interface{}, out *[]Violation, add func(string, ...interface{})) {
+	if required, ok := rules["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, present := v[name]; !present {
+					add("missing required property %q", name)
+				}
+			}
+		}
+	}
+	if n, ok := number(rules["minProperties"]); ok && float64(len(v)) < n {
+		add("expected at least %v properties, got %d", n, len(v))
+	}
+	if n, ok := number(rules["maxProperties"]); ok && float64(len(v)) > n {
+		add("expected at most %v properties, got %d", n, len(v))
+	}
+
+	properties, _ := rules["properties"].(map[string]interface{})
+	additional, restricted := rules["additionalProperties"]
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if sub, ok := properties[key]; ok {
+			validate(append(path, key), sub, v[key], out)
+		} else if restricted {
+			if additional == false {
+				*out = append(*out, Violation{Path: pointer(append(path, key)), Message: "property is not allowed"})
+			} else {
+				validate(append(path, key), additional, v[key], out)
+			}
+		}
+	}
+}
+
+// countMatches returns how many of the schemas the value satisfies.
+func countMatches(schemas []interface{}, value interface{}) int {
+	n := 0
+	for _, s := range schemas {
+		if len(Validate(s, value)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// typeList returns the types allowed by the "type" keyword, a string or a list of strings.
+func typeList(t interface{}) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// hasType reports whether the value is of one of the types. Integers are also numbers.
+func hasType(types []string, value interface{}) bool {
+	actual := typeOf(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type of a decoded JSON value.
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// uuidPattern matches the textual form of a UUID.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validFormat checks the string formats that are commonly used in API responses.
+// Other formats are always accepted.
+func validFormat(format, v string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", v)
+		return err == nil
+	case "email":
+		address, err := mail.ParseAddress(v)
+		return err == nil && address.Address == v
+	case "uuid":
+		return uuidPattern.MatchString(v)
+	case "uri":
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != ""
+	case "ipv4":
+		ip := net.ParseIP(v)
+		return ip != nil && ip.To4() != nil && !strings.Contains(v, ":")
+	case "ipv6":
+		ip := net.ParseIP(v)
+		return ip != nil && strings.Contains(v, ":")
+	}
+	return true
+}
+
+// number converts a numeric keyword value to float64.
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// contains reports whether the list holds a value equal to v.
+func contains(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if equal(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares two decoded JSON values.
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// compactJSON formats a value for a message.
+func compactJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// pointer builds a JSON pointer from path segments, escaping "~" and "/".
+func pointer(path []string) string {
+	var b strings.Builder
+	for _, segment := range path {
+		b.WriteByte('/')
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(segment))
+	}
+	return b.String()
+}
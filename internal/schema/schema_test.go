@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decode parses a JSON document for the tests.
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", s, err)
+	}
+	return v
+}
+
+func TestValidate(t *testing.T) {
+	const user = `{
+		"type": "object",
+		"required": ["id", "email"],
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"email": {"type": "string", "format": "email"},
+			"name": {"type": ["string", "null"], "maxLength": 5},
+			"role": {"enum": ["admin", "user"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true, "maxItems": 3}
+		},
+		"additionalProperties": false
+	}`
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []string // Violations as "pointer: message".
+	}{
+		{"valid object", user, `{"id": 1, "email": "ann@example.com", "name": null, "role": "admin", "tags": ["a", "b"]}`, nil},
+		{"object violations", user, `{"id": 0, "email": "not an email", "name": "Annabel", "role": "root", "tags": ["a", "a", 3, "b"], "extra": true}`, []string{
+			`/email: "not an email" is not a valid email`,
+			`/extra: property is not allowed`,
+			`/id: expected a value of at least 1, got 0`,
+			`/name: expected at most 5 characters, got 7`,
+			`/role: "root" is not one of ["admin","user"]`,
+			`/tags: expected at most 3 items, got 4`,
+			`/tags: items 0 and 1 are equal`,
+			`/tags/2: expected string, got integer`,
+		}},
+		{"missing properties", user, `{}`, []string{`/: missing required property "id"`, `/: missing required property "email"`}},
+		{"wrong root type", user, `[]`, []string{`/: expected object, got array`}},
+		{"integer is a number", `{"type": "number", "multipleOf": 0.5}`, `2`, nil},
+		{"number is not an integer", `{"type": "integer"}`, `2.5`, []string{`/: expected integer, got number`}},
+		{"multipleOf", `{"multipleOf": 0.1}`, `0.35`, []string{`/: 0.35 is not a multiple of 0.1`}},
+		{"OpenAPI 3.0 exclusive bounds", `{"minimum": 1, "exclusiveMinimum": true, "maximum": 5, "exclusiveMaximum": true}`, `5`, []string{`/: expected a value less than 5, got 5`}},
+		{"OpenAPI 3.1 exclusive bounds", `{"exclusiveMinimum": 1}`, `1`, []string{`/: expected a value greater than 1, got 1`}},
+		{"nullable", `{"type": "string", "nullable": true}`, `null`, nil},
+		{"not nullable", `{"type": "string"}`, `null`, []string{`/: expected string, got null`}},
+		{"const", `{"const": {"a": [1]}}`, `{"a": [2]}`, []string{`/: expected {"a":[1]}, got {"a":[2]}`}},
+		{"pattern and length in characters", `{"pattern": "^[a-z]+$", "minLength": 3}`, `"ñu"`, []string{`/: expected at least 3 characters, got 2`, `/: "ñu" does not match the pattern "^[a-z]+$"`}},
+		{"list of item schemas is ignored", `{"type": "array", "items": [{"format": "uuid"}]}`, `["x"]`, nil},
+		{"format list", `{"properties": {"d": {"format": "date"}, "t": {"format": "date-time"}, "u": {"format": "uuid"}, "i": {"format": "ipv4"}, "w": {"format": "uri"}, "z": {"format": "custom"}}}`,
+			`{"d": "2024-02-30", "t": "2024-01-01T10:00:00Z", "u": "3f2504e0-4f89-41d3-9a0c-0305e82c3301", "i": "::1", "w": "/relative", "z": "anything"}`,
+			[]string{`/d: "2024-02-30" is not a valid date`, `/i: "::1" is not a valid ipv4`, `/w: "/relative" is not a valid uri`}},
+		{"additional properties schema", `{"additionalProperties": {"type": "integer"}}`, `{"a": 1, "b": "2"}`, []string{`/b: expected integer, got string`}},
+		{"allOf reported once", `{"allOf": [{"required": ["a"]}, {"required": ["a"]}]}`, `{}`, []string{`/: missing required property "a"`}},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, []string{`/: does not match any of the 2 allowed schemas`}},
+		{"oneOf", `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, []string{`/: matches 2 of the 2 schemas instead of exactly one`}},
+		{"not", `{"not": {"type": "null"}}`, `null`, []string{`/: must not match the schema in "not"`}},
+		{"false schema", `{"properties": {"gone": false}}`, `{"gone": 1}`, []string{`/gone: no value is allowed here`}},
+		{"escaped pointer", `{"properties": {"a/b": {"properties": {"~c": {"type": "string"}}}}}`, `{"a/b": {"~c": 1}}`, []string{`/a~1b/~0c: expected string, got integer`}},
+		{"unknown keywords", `{"x-custom": 1, "description": "anything"}`, `{"a": 1}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range Validate(decode(t, tt.schema), decode(t, tt.value)) {
+				got = append(got, v.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "user.json")
+	if err := os.WriteFile(file, []byte(`{"type": "object"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{"inline", ` {"type": "object"} `, ""},
+		{"file", file, ""},
+		{"missing file", filepath.Join(t.TempDir(), "missing.json"), "error reading schema file"},
+		{"invalid inline", `{"type": `, "error parsing schema"},
+		{"not an object", writeFile(t, "list.json", `[1]`), "not a JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Load(tt.source)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(s, map[string]interface{}{"type": "object"}) {
+				t.Errorf("Load() = %v", s)
+			}
+		})
+	}
+}
+
+// writeFile writes a temporary file and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
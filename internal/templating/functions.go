@@ -0,0 +1,269 @@
+package templating
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Generator produces the random values used by template functions.
+// All randomness comes from a single seeded source, so rendering the same
+// suite with the same seed always yields the same values (except for now).
+type Generator struct {
+	Seed int64 // Seed used to initialize the random source.
+	rnd  *rand.Rand
+}
+
+// NewGenerator creates a Generator whose random source is initialized with seed.
+//
+// Parameters:
+//   - seed (int64): The seed to use; record it to reproduce a run.
+//
+// Returns:
+//   - *Generator: The new generator.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{Seed: seed, rnd: rand.New(rand.NewSource(seed))}
+}
+
+// function is a built-in template function. It receives the already evaluated arguments.
+type function func(gen *Generator, args []string) (string, error)
+
+// functions lists the built-in template functions by name.
+var functions = map[string]function{
+	"uuid":       uuidFunc,
+	"randInt":    randIntFunc,
+	"randString": randStringFunc,
+	"now":        nowFunc,
+	"base64":     base64Func,
+	"sha256":     sha256Func,
+	"urlencode":  urlencodeFunc,
+}
+
+// fakeFields lists the fake data generators, used as {{fake.name}}.
+var fakeFields = map[string]func(gen *Generator) string{
+	"firstName": func(gen *Generator) string { return pick(gen, firstNames) },
+	"lastName":  func(gen *Generator) string { return pick(gen, lastNames) },
+	"name": func(gen *Generator) string {
+		return pick(gen, firstNames) + " " + pick(gen, lastNames)
+	},
+	"email": func(gen *Generator) string {
+		user := strings.ToLower(pick(gen, firstNames) + "." + pick(gen, lastNames))
+		return fmt.Sprintf("%s%d@%s", user, gen.rnd.Intn(1000), pick(gen, domains))
+	},
+	"phone": func(gen *Generator) string {
+		return fmt.Sprintf("555-%03d-%04d", gen.rnd.Intn(1000), gen.rnd.Intn(10000))
+	},
+	"street": func(gen *Generator) string {
+		return fmt.Sprintf("%d %s", 1+gen.rnd.Intn(999), pick(gen, streets))
+	},
+	"city":    func(gen *Generator) string { return pick(gen, cities) },
+	"zipcode": func(gen *Generator) string { return fmt.Sprintf("%05d", gen.rnd.Intn(100000)) },
+	"address": func(gen *Generator) string {
+		return fmt.Sprintf("%d %s, %s %05d", 1+gen.rnd.Intn(999), pick(gen, streets), pick(gen, cities), gen.rnd.Intn(100000))
+	},
+}
+
+var (
+	firstNames = []string{"Alice", "Bruno", "Carmen", "David", "Elena", "Farid", "Grace", "Hugo", "Irene", "Jorge", "Kenji", "Laura", "Marta", "Nico", "Olga", "Pablo"}
+	lastNames  = []string{"Smith", "García", "Müller", "Rossi", "Dubois", "Silva", "Novak", "Kowalski", "Tanaka", "Jensen", "López", "Costa", "Fischer", "Martin"}
+	streets    = []string{"Main Street", "Oak Avenue", "Elm Road", "Maple Lane", "Harbor Drive", "Sunset Boulevard", "Mill Street", "Church Road"}
+	cities     = []string{"Springfield", "Riverton", "Lakeside", "Fairview", "Greenville", "Kingston", "Milton", "Ashford"}
+	domains    = []string{"example.com", "example.org", "example.net"}
+)
+
+// callFunction evaluates a function call expression such as `randInt 1 10`.
+// The boolean result is false when name is not a built-in function.
+func callFunction(name string, rawArgs []string, ctx Context) (string, bool, error) {
+	if field, ok := strings.CutPrefix(name, "fake."); ok {
+		gen, ok := fakeFields[field]
+		if !ok || ctx.Gen == nil {
+			return "", false, nil
+		}
+		if len(rawArgs) > 0 {
+			return "", true, fmt.Errorf("fake.%s takes no arguments", field)
+		}
+		return gen(ctx.Gen), true, nil
+	}
+
+	fn, ok := functions[name]
+	if !ok || ctx.Gen == nil {
+		return "", false, nil
+	}
+	args := make([]string, len(rawArgs))
+	for i, raw := range rawArgs {
+		value, err := evaluateArg(raw, ctx)
+		if err != nil {
+			return "", true, err
+		}
+		args[i] = value
+	}
+	value, err := fn(ctx.Gen, args)
+	return value, true, err
+}
+
+// evaluateArg resolves a function argument: a quoted string, a row reference or a bare word.
+func evaluateArg(raw string, ctx Context) (string, error) {
+	if strings.HasPrefix(raw, `"`) {
+		return strconv.Unquote(raw)
+	}
+	if path, ok := strings.CutPrefix(raw, "row."); ok {
+		return lookupRow(ctx.Row, path)
+	}
+	return raw, nil
+}
+
+// splitArgs splits an expression into words, keeping double-quoted strings together.
+func splitArgs(expr string) ([]string, error) {
+	var words []string
+	for expr = strings.TrimSpace(expr); expr != ""; expr = strings.TrimSpace(expr) {
+		if expr[0] == '"' {
+			end := 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			words = append(words, expr[:end+1])
+			expr = expr[end+1:]
+			continue
+		}
+		end := strings.IndexAny(expr, " \t")
+		if end < 0 {
+			end = len(expr)
+		}
+		words = append(words, expr[:end])
+		expr = expr[end:]
+	}
+	return words, nil
+}
+
+// pick returns a random element of values.
+func pick(gen *Generator, values []string) string {
+	return values[gen.rnd.Intn(len(values))]
+}
+
+// uuidFunc returns a random (version 4) UUID: {{uuid}}.
+func uuidFunc(gen *Generator, args []string) (string, error) {
+	if len(args) > 0 {
+		return "", fmt.Errorf("uuid takes no arguments")
+	}
+	var b [16]byte
+	gen.rnd.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// randIntFunc returns a random integer between min and max, both included: {{randInt 1 100}}.
+func randIntFunc(gen *Generator, args []string) (string, error) {
+	if len(args) != 2 {
+		return "", fmt.Errorf("randInt expects 2 arguments (min max)")
+	}
+	min, err1 := strconv.Atoi(args[0])
+	max, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil || min > max {
+		return "", fmt.Errorf("randInt expects two integers with min <= max")
+	}
+	return strconv.Itoa(min + gen.rnd.Intn(max-min+1)), nil
+}
+
+// randStringFunc returns a random alphanumeric string of a fixed length, {{randString 8}},
+// or of a length within a range, {{randString 5 10}}.
+func randStringFunc(gen *Generator, args []string) (string, error) {
+	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	if len(args) == 1 {
+		args = append(args, args[0])
+	}
+	if len(args) != 2 {
+		return "", fmt.Errorf("randString expects 1 or 2 arguments (length, or min max)")
+	}
+	min, err1 := strconv.Atoi(args[0])
+	max, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil || min < 0 || min > max {
+		return "", fmt.Errorf("randString expects non-negative lengths with min <= max")
+	}
+	b := make([]byte, min+gen.rnd.Intn(max-min+1))
+	for i := range b {
+		b[i] = charset[gen.rnd.Intn(len(charset))]
+	}
+	return string(b), nil
+}
+
+// nowFunc returns the current time, optionally formatted with a Go layout (or "unix",
+// "unixmilli") and shifted by an offset such as "-1h30m" or "+7d": {{now "2006-01-02" "+1d"}}.
+func nowFunc(gen *Generator, args []string) (string, error) {
+	if len(args) > 2 {
+		return "", fmt.Errorf("now expects at most 2 arguments (format offset)")
+	}
+	t := time.Now()
+	if len(args) == 2 {
+		offset, err := parseOffset(args[1])
+		if err != nil {
+			return "", err
+		}
+		t = t.Add(offset)
+	}
+
+	layout := time.RFC3339
+	if len(args) > 0 && args[0] != "" {
+		layout = args[0]
+	}
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10), nil
+	case "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10), nil
+	}
+	return t.Format(layout), nil
+}
+
+// parseOffset parses a duration accepted by time.ParseDuration, plus whole days ("7d").
+func parseOffset(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset %q", s)
+	}
+	return d, nil
+}
+
+// base64Func returns the standard base64 encoding of its argument: {{base64 "user:pass"}}.
+func base64Func(gen *Generator, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("base64 expects 1 argument")
+	}
+	return base64.StdEncoding.EncodeToString([]byte(args[0])), nil
+}
+
+// sha256Func returns the hex-encoded SHA-256 digest of its argument: {{sha256 "text"}}.
+func sha256Func(gen *Generator, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("sha256 expects 1 argument")
+	}
+	sum := sha256.Sum256([]byte(args[0]))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// urlencodeFunc escapes its argument for use in a URL query: {{urlencode "a b&c"}}.
+func urlencodeFunc(gen *Generator, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("urlencode expects 1 argument")
+	}
+	return url.QueryEscape(args[0]), nil
+}
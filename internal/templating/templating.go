@@ -0,0 +1,139 @@
+// Package templating renders the {{...}} expressions that may appear in test case fields.
+package templating
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api-testing/models"
+	"regexp"
+	"strings"
+)
+
+// Context holds the values that template expressions can refer to.
+type Context struct {
+	Row map[string]interface{} // Current data row, referenced as {{row.field}} (nil if the test is not data-driven).
+	Gen *Generator             // Source of generated values for template functions (nil disables them).
+}
+
+// exprPattern matches a single {{ expression }} placeholder.
+var exprPattern = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+
+// Render replaces every {{ expression }} in s with its value.
+// Expressions that are not recognized are left untouched, so literal double
+// braces in bodies or headers keep working.
+//
+// Supported expressions:
+//   - row.field: The value of field in the current data row. Nested JSON values can be
+//     reached with dots (row.address.city); non-string values are rendered as JSON.
+//   - uuid: A random version 4 UUID.
+//   - randInt min max: A random integer in [min, max].
+//   - randString n / randString min max: A random alphanumeric string.
+//   - now [format] [offset]: The current time as RFC 3339, a Go layout, "unix" or
+//     "unixmilli", shifted by an offset such as "-2h" or "+7d".
+//   - base64 value, sha256 value, urlencode value: Encodings of a value.
+//   - fake.name, fake.firstName, fake.lastName, fake.email, fake.phone, fake.street,
+//     fake.city, fake.zipcode, fake.address: Fake personal data.
+//
+// Function arguments are bare words, double-quoted strings or row.field references.
+//
+// Parameters:
+//   - s (string): The text to render.
+//   - ctx (Context): The values available to the expressions.
+//
+// Returns:
+//   - string: The rendered text.
+//   - error: An error if an expression refers to a missing value.
+func Render(s string, ctx Context) (string, error) {
+	var renderErr error
+	out := exprPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if renderErr != nil {
+			return match
+		}
+		expr := exprPattern.FindStringSubmatch(match)[1]
+		value, ok, err := evaluate(expr, ctx)
+		if err != nil {
+			renderErr = fmt.Errorf("{{%s}}: %v", expr, err)
+			return match
+		}
+		if !ok {
+			return match
+		}
+		return value
+	})
+	return out, renderErr
+}
+
+// RenderTestCase renders every text field of a test case, leaving its identifier,
+// Run flag and dependency lists untouched.
+//
+// Parameters:
+//   - tc (models.TestCase): The test case to render.
+//   - ctx (Context): The values available to the expressions.
+//
+// Returns:
+//   - models.TestCase: A copy of the test case with all expressions replaced.
+//   - error: An error naming the first field that could not be rendered.
+func RenderTestCase(tc models.TestCase, ctx Context) (models.TestCase, error) {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"TestCase", &tc.TestCase},
+		{"Method", &tc.Method},
+		{"URL", &tc.URL},
+		{"Endpoint", &tc.Endpoint},
+		{"Authorization", &tc.Authorization},
+		{"User", &tc.User},
+		{"Password", &tc.Password},
+		{"Headers", &tc.Headers},
+		{"Body", &tc.Body},
+		{"ExpectedResponse", &tc.ExpectedResponse},
+	}
+	for _, f := range fields {
+		rendered, err := Render(*f.value, ctx)
+		if err != nil {
+			return tc, fmt.Errorf("test %s, field %s: %v", tc.TestId, f.name, err)
+		}
+		*f.value = rendered
+	}
+	return tc, nil
+}
+
+// evaluate computes the value of a single expression.
+// The boolean result is false when the expression is not a known one.
+func evaluate(expr string, ctx Context) (string, bool, error) {
+	words, err := splitArgs(expr)
+	if err != nil || len(words) == 0 {
+		return "", false, nil
+	}
+	if path, ok := strings.CutPrefix(words[0], "row."); ok && len(words) == 1 {
+		value, err := lookupRow(ctx.Row, path)
+		return value, true, err
+	}
+	return callFunction(words[0], words[1:], ctx)
+}
+
+// lookupRow resolves a dotted path inside a data row.
+func lookupRow(row map[string]interface{}, path string) (string, error) {
+	if row == nil {
+		return "", fmt.Errorf("test case has no data row")
+	}
+	var current interface{} = row
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("field %q is not an object in data row", path)
+		}
+		if current, ok = obj[key]; !ok {
+			return "", fmt.Errorf("field %q not found in data row", path)
+		}
+	}
+	if s, ok := current.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(current)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
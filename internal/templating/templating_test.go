@@ -0,0 +1,142 @@
+package templating
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderIsReproducibleWithSeed(t *testing.T) {
+	const tmpl = `{{uuid}} {{randInt 1 1000000}} {{randString 12}} {{randString 3 9}} {{fake.name}} {{fake.email}} {{fake.phone}} {{fake.address}}`
+
+	render := func(seed int64) string {
+		t.Helper()
+		out, err := Render(tmpl, Context{Gen: NewGenerator(seed)})
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		return out
+	}
+	first, again, other := render(42), render(42), render(43)
+	if first != again {
+		t.Errorf("same seed rendered differently:\n%s\n%s", first, again)
+	}
+	if first == other {
+		t.Errorf("different seeds rendered the same values: %s", first)
+	}
+	if strings.Contains(first, "{{") {
+		t.Errorf("Render() left expressions unrendered: %s", first)
+	}
+}
+
+func TestRenderFunctions(t *testing.T) {
+	ctx := Context{Row: map[string]interface{}{"user": "ann", "n": 3.0}, Gen: NewGenerator(7)}
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{`{{base64 "user:pass"}}`, "dXNlcjpwYXNz"},
+		{`{{ sha256 "abc" }}`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{`{{urlencode "a b&c=d"}}`, "a+b%26c%3Dd"},
+		{`{{base64 row.user}}`, "YW5u"},
+		{`{{row.n}}-{{row.user}}`, "3-ann"},
+		{`{{randInt 4 4}}`, "4"},
+		{`{{randString 0}}`, ""},
+		{`{{ unknown 1 }} and {{}} stay`, `{{ unknown 1 }} and {{}} stay`},
+		{`{"a":{"b":1}}`, `{"a":{"b":1}}`},
+	}
+	for _, tt := range tests {
+		got, err := Render(tt.tmpl, ctx)
+		if err != nil {
+			t.Errorf("Render(%s) error = %v", tt.tmpl, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Render(%s) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestRenderRandomValues(t *testing.T) {
+	ctx := Context{Gen: NewGenerator(1)}
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	alphanumeric := regexp.MustCompile(`^[a-zA-Z0-9]*$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 300; i++ {
+		uuid, _ := Render("{{uuid}}", ctx)
+		if !uuidPattern.MatchString(uuid) {
+			t.Fatalf("uuid %q is not a version 4 UUID", uuid)
+		}
+
+		n, _ := Render("{{randInt -2 2}}", ctx)
+		value, err := strconv.Atoi(n)
+		if err != nil || value < -2 || value > 2 {
+			t.Fatalf("randInt -2 2 = %q, want an integer in [-2, 2]", n)
+		}
+		seen[n] = true
+
+		s, _ := Render("{{randString 2 5}}", ctx)
+		if len(s) < 2 || len(s) > 5 || !alphanumeric.MatchString(s) {
+			t.Fatalf("randString 2 5 = %q, want 2 to 5 alphanumeric characters", s)
+		}
+	}
+	if len(seen) != 5 {
+		t.Errorf("randInt -2 2 produced %d distinct values, want 5", len(seen))
+	}
+}
+
+func TestRenderNow(t *testing.T) {
+	ctx := Context{Gen: NewGenerator(1)}
+	before := time.Now()
+
+	out, err := Render(`{{now}}`, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, err := time.Parse(time.RFC3339, out); err != nil || parsed.Before(before.Truncate(time.Second)) {
+		t.Errorf("now = %q, want the current RFC 3339 time", out)
+	}
+
+	out, _ = Render(`{{now "2006-01-02" "+2d"}}`, ctx)
+	if want := before.Add(48 * time.Hour).Format("2006-01-02"); out != want {
+		t.Errorf(`now "2006-01-02" "+2d" = %q, want %q`, out, want)
+	}
+
+	out, _ = Render(`{{now unix -1h}}`, ctx)
+	unix, err := strconv.ParseInt(out, 10, 64)
+	if want := before.Add(-time.Hour).Unix(); err != nil || unix < want || unix > want+5 {
+		t.Errorf("now unix -1h = %q, want about %d", out, want)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	ctx := Context{Gen: NewGenerator(1)}
+	tests := []struct {
+		tmpl    string
+		wantErr string
+	}{
+		{`{{uuid 1}}`, "uuid takes no arguments"},
+		{`{{randInt 5 1}}`, "min <= max"},
+		{`{{randInt a 1}}`, "two integers"},
+		{`{{randString -1}}`, "non-negative lengths"},
+		{`{{now "" "tomorrow"}}`, `invalid offset "tomorrow"`},
+		{`{{fake.name x}}`, "fake.name takes no arguments"},
+		{`{{base64}}`, "base64 expects 1 argument"},
+		{`{{row.id}}`, "test case has no data row"},
+	}
+	for _, tt := range tests {
+		if _, err := Render(tt.tmpl, ctx); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Render(%s) error = %v, want %q", tt.tmpl, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRenderWithoutGenerator(t *testing.T) {
+	const tmpl = "{{uuid}} {{fake.name}} {{randInt 1 2}}"
+	out, err := Render(tmpl, Context{})
+	if err != nil || out != tmpl {
+		t.Errorf("Render() without generator = %q, %v; want the template untouched", out, err)
+	}
+}
@@ -0,0 +1,376 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api-testing/internal/openapi"
+	"go-api-testing/internal/schema"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CompareOptions tunes how the expected response is compared with the obtained one.
+// It is read from the CompareOptions column of a test case as a JSON object, e.g.
+//
+//	{"ignorePaths":["/createdAt","/items/*/id"],"unorderedArrays":true,"absTolerance":0.01}
+type CompareOptions struct {
+	IgnorePaths       []string `json:"ignorePaths"`       // JSON pointers to skip; "*" matches any key or index.
+	UnorderedArrays   bool     `json:"unorderedArrays"`   // Compare arrays as sets, ignoring element order.
+	AbsTolerance      float64  `json:"absTolerance"`      // Maximum absolute difference between numbers.
+	RelTolerance      float64  `json:"relTolerance"`      // Maximum difference between numbers, relative to the expected value.
+	IgnoreExtraFields bool     `json:"ignoreExtraFields"` // Allow objects in the response to have fields that are not expected.
+}
+
+// ParseCompareOptions decodes the CompareOptions column of a test case.
+// An empty string yields the default options (exact comparison).
+//
+// Parameters:
+//   - raw (string): The JSON object with the options.
+//
+// Returns:
+//   - CompareOptions: The decoded options.
+//   - error: An error if the JSON is invalid or contains unknown options.
+func ParseCompareOptions(raw string) (CompareOptions, error) {
+	var opts CompareOptions
+	if strings.TrimSpace(raw) == "" {
+		return opts, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&opts); err != nil {
+		return opts, err
+	}
+	for _, p := range opts.IgnorePaths {
+		if p != "" && !strings.HasPrefix(p, "/") {
+			return opts, fmt.Errorf("ignore path %q must be a JSON pointer starting with '/'", p)
+		}
+	}
+	return opts, nil
+}
+
+// DiffKind classifies a difference between the expected and the obtained response.
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"   // The value is present in the response but not expected.
+	DiffRemoved DiffKind = "removed" // The value is expected but missing from the response.
+	DiffChanged DiffKind = "changed" // The value is present in both but differs.
+)
+
+// Difference is a single path-level difference between the expected and the obtained response.
+type Difference struct {
+	Path string      `json:"path"`          // JSON pointer to the value (empty for the whole document).
+	Kind DiffKind    `json:"kind"`          // Type of difference.
+	Old  interface{} `json:"old,omitempty"` // Expected value (unset for added values).
+	New  interface{} `json:"new,omitempty"` // Obtained value (unset for removed values).
+}
+
+// diffJSON lists the differences between expected and actual under the given options.
+// An empty result means that the documents match.
+func diffJSON(expected, actual interface{}, opts CompareOptions) []Difference {
+	var diffs []Difference
+	opts.diff(nil, expected, actual, &diffs)
+	return diffs
+}
+
+// diff compares two decoded JSON values located at path and appends their differences to diffs.
+func (o CompareOptions) diff(path []string, expected, actual interface{}, diffs *[]Difference) {
+	if o.ignored(path) {
+		return
+	}
+	changed := func() {
+		*diffs = append(*diffs, Difference{Path: pointer(path), Kind: DiffChanged, Old: expected, New: actual})
+	}
+
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			changed()
+			return
+		}
+		for _, key := range sortedKeys(exp) {
+			child := append(path[:len(path):len(path)], key)
+			if actValue, ok := act[key]; ok {
+				o.diff(child, exp[key], actValue, diffs)
+			} else if !o.ignored(child) {
+				*diffs = append(*diffs, Difference{Path: pointer(child), Kind: DiffRemoved, Old: exp[key]})
+			}
+		}
+		if !o.IgnoreExtraFields {
+			for _, key := range sortedKeys(act) {
+				child := append(path[:len(path):len(path)], key)
+				if _, ok := exp[key]; !ok && !o.ignored(child) {
+					*diffs = append(*diffs, Difference{Path: pointer(child), Kind: DiffAdded, New: act[key]})
+				}
+			}
+		}
+
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			changed()
+			return
+		}
+		if o.UnorderedArrays {
+			o.diffUnordered(path, exp, act, diffs)
+			return
+		}
+		for i := 0; i < len(exp) || i < len(act); i++ {
+			child := append(path[:len(path):len(path)], strconv.Itoa(i))
+			switch {
+			case i >= len(act):
+				if !o.ignored(child) {
+					*diffs = append(*diffs, Difference{Path: pointer(child), Kind: DiffRemoved, Old: exp[i]})
+				}
+			case i >= len(exp):
+				if !o.ignored(child) {
+					*diffs = append(*diffs, Difference{Path: pointer(child), Kind: DiffAdded, New: act[i]})
+				}
+			default:
+				o.diff(child, exp[i], act[i], diffs)
+			}
+		}
+
+	case float64:
+		if act, ok := actual.(float64); !ok || !o.numbersMatch(exp, act) {
+			changed()
+		}
+
+	default:
+		if expected != actual {
+			changed()
+		}
+	}
+}
+
+// diffUnordered pairs every expected element with a distinct matching actual element.
+// Identical elements are paired first; the pairing is then completed with a maximum
+// bipartite matching, so that tolerances and ignored paths never cause a mismatch
+// when a valid pairing exists. Expected elements left without a match are reported
+// as removed and unmatched actual elements as added.
+func (o CompareOptions) diffUnordered(path []string, expected, actual []interface{}, diffs *[]Difference) {
+	// compatible[i][j] reports whether expected[i] matches actual[j] (nil if expected[i] is ignored)
+	compatible := make([][]bool, len(expected))
+	for i, exp := range expected {
+		child := append(path[:len(path):len(path)], strconv.Itoa(i))
+		if o.ignored(child) {
+			continue
+		}
+		compatible[i] = make([]bool, len(actual))
+		for j, act := range actual {
+			compatible[i][j] = len(diffJSON(exp, act, o.relativeTo(child))) == 0
+		}
+	}
+
+	matchedExp := make([]int, len(expected)) // Index of the actual element paired with each expected one, or -1.
+	matchedAct := make([]int, len(actual))   // Index of the expected element paired with each actual one, or -1.
+	for i := range matchedExp {
+		matchedExp[i] = -1
+	}
+	for j := range matchedAct {
+		matchedAct[j] = -1
+	}
+	for i := range expected {
+		for j := range actual {
+			if compatible[i] != nil && matchedAct[j] < 0 && reflect.DeepEqual(expected[i], actual[j]) {
+				matchedExp[i], matchedAct[j] = j, i
+				break
+			}
+		}
+	}
+
+	// augment looks for an alternating path that pairs expected[i], re-pairing others if needed
+	var augment func(i int, visited []bool) bool
+	augment = func(i int, visited []bool) bool {
+		for j, ok := range compatible[i] {
+			if !ok || visited[j] {
+				continue
+			}
+			visited[j] = true
+			if matchedAct[j] < 0 || augment(matchedAct[j], visited) {
+				matchedExp[i], matchedAct[j] = j, i
+				return true
+			}
+		}
+		return false
+	}
+	for i := range expected {
+		if compatible[i] != nil && matchedExp[i] < 0 {
+			augment(i, make([]bool, len(actual)))
+		}
+	}
+
+	for i, exp := range expected {
+		if compatible[i] != nil && matchedExp[i] < 0 {
+			child := append(path[:len(path):len(path)], strconv.Itoa(i))
+			*diffs = append(*diffs, Difference{Path: pointer(child), Kind: DiffRemoved, Old: exp})
+		}
+	}
+	for j, act := range actual {
+		child := append(path[:len(path):len(path)], strconv.Itoa(j))
+		if matchedAct[j] < 0 && !o.ignored(child) {
+			*diffs = append(*diffs, Difference{Path: pointer(child), Kind: DiffAdded, New: act})
+		}
+	}
+}
+
+// relativeTo returns options whose ignored paths are relative to the value at path,
+// so that a nested document can be compared on its own.
+func (o CompareOptions) relativeTo(path []string) CompareOptions {
+	relative := o
+	relative.IgnorePaths = nil
+	for _, p := range o.IgnorePaths {
+		segments := strings.Split(p, "/")[1:]
+		if len(segments) <= len(path) {
+			continue
+		}
+		if pointerMatches("/"+strings.Join(segments[:len(path)], "/"), path) {
+			relative.IgnorePaths = append(relative.IgnorePaths, "/"+strings.Join(segments[len(path):], "/"))
+		}
+	}
+	return relative
+}
+
+// summarizeDiff returns a one-line overview of the differences, e.g.
+// "3 differences: 1 changed, 1 removed, 1 added".
+func summarizeDiff(diffs []Difference) string {
+	counts := map[DiffKind]int{}
+	for _, d := range diffs {
+		counts[d.Kind]++
+	}
+	var parts []string
+	for _, kind := range []DiffKind{DiffChanged, DiffRemoved, DiffAdded} {
+		if counts[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[kind], kind))
+		}
+	}
+	noun := "differences"
+	if len(diffs) == 1 {
+		noun = "difference"
+	}
+	return fmt.Sprintf("%d %s: %s", len(diffs), noun, strings.Join(parts, ", "))
+}
+
+// formatDiff renders the differences as a compact list, one per line, showing at most
+// limit entries, e.g. `~ /name: "Ann" -> "Bob"`, `- /id: 1` and `+ /extra: true`.
+func formatDiff(diffs []Difference, limit int) string {
+	var lines []string
+	for i, d := range diffs {
+		if i == limit {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(diffs)-limit))
+			break
+		}
+		path := d.Path
+		if path == "" {
+			path = "/"
+		}
+		switch d.Kind {
+		case DiffAdded:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", path, compactJSON(d.New)))
+		case DiffRemoved:
+			lines = append(lines, fmt.Sprintf("- %s: %s", path, compactJSON(d.Old)))
+		default:
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", path, compactJSON(d.Old), compactJSON(d.New)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatViolations renders schema violations one per line, showing at most limit
+// entries, e.g. `! /id: expected integer, got string`.
+func formatViolations(violations []schema.Violation, limit int) string {
+	var lines []string
+	for i, v := range violations {
+		if i == limit {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(violations)-limit))
+			break
+		}
+		lines = append(lines, "! "+v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatContract renders contract violations one per line, showing at most limit
+// entries, e.g. `! status: status 418 is not documented for GET /users (documented: 200)`.
+func formatContract(violations []openapi.Violation, limit int) string {
+	var lines []string
+	for i, v := range violations {
+		if i == limit {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(violations)-limit))
+			break
+		}
+		lines = append(lines, "! "+v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// compactJSON encodes a value as single-line JSON, shortening long values. Values are
+// cut on characters, so non-ASCII text is never split in the middle of a character.
+func compactJSON(v interface{}) string {
+	const maxLen = 60
+	b, _ := json.Marshal(v)
+	if runes := []rune(string(b)); len(runes) > maxLen {
+		return string(runes[:maxLen-3]) + "..."
+	}
+	return string(b)
+}
+
+// pointer encodes a path as a JSON pointer (RFC 6901).
+func pointer(path []string) string {
+	var b strings.Builder
+	for _, segment := range path {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// sortedKeys returns the keys of an object in alphabetical order, so diffs are stable.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// numbersMatch compares two numbers using the absolute and relative tolerances.
+func (o CompareOptions) numbersMatch(expected, actual float64) bool {
+	diff := math.Abs(expected - actual)
+	return diff == 0 ||
+		(o.AbsTolerance > 0 && diff <= o.AbsTolerance) ||
+		(o.RelTolerance > 0 && diff <= o.RelTolerance*math.Abs(expected))
+}
+
+// ignored reports whether path matches one of the ignored JSON pointers.
+func (o CompareOptions) ignored(path []string) bool {
+	for _, pointer := range o.IgnorePaths {
+		if pointerMatches(pointer, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// pointerMatches reports whether a JSON pointer (with optional "*" segments) designates path.
+func pointerMatches(pointer string, path []string) bool {
+	if pointer == "" {
+		return false
+	}
+	segments := strings.Split(pointer, "/")[1:]
+	if len(segments) != len(path) {
+		return false
+	}
+	for i, segment := range segments {
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
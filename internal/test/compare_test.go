@@ -0,0 +1,179 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// decode parses a JSON document for the comparison tests.
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", s, err)
+	}
+	return v
+}
+
+func TestParseCompareOptions(t *testing.T) {
+	opts, err := ParseCompareOptions(`{"ignorePaths":["/a","/b/*/c"],"unorderedArrays":true,"absTolerance":0.5,"relTolerance":0.1,"ignoreExtraFields":true}`)
+	if err != nil {
+		t.Fatalf("ParseCompareOptions() error = %v", err)
+	}
+	if len(opts.IgnorePaths) != 2 || !opts.UnorderedArrays || opts.AbsTolerance != 0.5 || opts.RelTolerance != 0.1 || !opts.IgnoreExtraFields {
+		t.Errorf("ParseCompareOptions() = %+v", opts)
+	}
+
+	if opts, err := ParseCompareOptions("  "); err != nil || opts.UnorderedArrays || opts.IgnorePaths != nil {
+		t.Errorf("ParseCompareOptions(blank) = %+v, %v; want the defaults", opts, err)
+	}
+	for _, raw := range []string{`{"unordered":true}`, `{"ignorePaths":["a"]}`, `[1]`} {
+		if _, err := ParseCompareOptions(raw); err == nil {
+			t.Errorf("ParseCompareOptions(%s) succeeded, want an error", raw)
+		}
+	}
+}
+
+func TestDiffJSONWithOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     string
+		expected string
+		actual   string
+		want     []string // Differences as "kind path".
+	}{
+		{"exact match", ``, `{"a":[1,{"b":null}]}`, `{"a":[1,{"b":null}]}`, nil},
+		{"ignored path", `{"ignorePaths":["/createdAt"]}`, `{"id":1,"createdAt":"x"}`, `{"id":1,"createdAt":"y"}`, nil},
+		{"ignored missing and extra fields", `{"ignorePaths":["/gone","/extra"]}`, `{"gone":1}`, `{"extra":2}`, nil},
+		{"wildcard ignore", `{"ignorePaths":["/items/*/id"]}`, `{"items":[{"id":1,"n":"a"},{"id":2,"n":"b"}]}`, `{"items":[{"id":7,"n":"a"},{"id":8,"n":"c"}]}`, []string{"changed /items/1/n"}},
+		{"extra fields", ``, `{"a":1}`, `{"a":1,"b":2}`, []string{"added /b"}},
+		{"extra fields allowed", `{"ignoreExtraFields":true}`, `{"a":{"x":1}}`, `{"a":{"x":1,"y":2},"b":2}`, nil},
+		{"absolute tolerance", `{"absTolerance":0.01}`, `[1.0, 2.0]`, `[1.005, 2.02]`, []string{"changed /1"}},
+		{"relative tolerance", `{"relTolerance":0.1}`, `[100, -10]`, `[109, -11.5]`, []string{"changed /1"}},
+		{"number against string", `{"absTolerance":1}`, `{"n":1}`, `{"n":"1"}`, []string{"changed /n"}},
+		{"ordered arrays", ``, `[1,2,3]`, `[3,2,1]`, []string{"changed /0", "changed /2"}},
+		{"unordered arrays", `{"unorderedArrays":true}`, `[1,2,[3,4]]`, `[[4,3],2,1]`, nil},
+		{"unordered with duplicates", `{"unorderedArrays":true}`, `[1,1,2]`, `[1,2,2]`, []string{"removed /1", "added /2"}},
+		{"unordered needs re-pairing", `{"unorderedArrays":true,"absTolerance":0.06}`, `[1.0, 1.1]`, `[1.05, 0.95]`, nil},
+		{"unordered prefers exact pairs", `{"unorderedArrays":true,"absTolerance":0.5}`, `[1, 1.4]`, `[1.4, 1, 9]`, []string{"added /2"}},
+		{"unordered with ignored fields", `{"unorderedArrays":true,"ignorePaths":["/*/id"]}`, `[{"id":1,"n":"a"},{"id":2,"n":"a"},{"id":3,"n":"b"}]`, `[{"id":9,"n":"b"},{"id":8,"n":"a"},{"id":7,"n":"a"}]`, nil},
+		{"unordered re-pairing with ignored fields", `{"unorderedArrays":true,"ignorePaths":["/*/t"]}`, `[{"n":1},{"n":1,"t":0}]`, `[{"n":1,"t":5},{"n":1}]`, nil},
+		{"unordered ignored element", `{"unorderedArrays":true,"ignorePaths":["/0"]}`, `["x","y"]`, `["y"]`, nil},
+		{"unordered length mismatch", `{"unorderedArrays":true}`, `[1,2]`, `[2]`, []string{"removed /0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := ParseCompareOptions(tt.opts)
+			if err != nil {
+				t.Fatalf("ParseCompareOptions() error = %v", err)
+			}
+			var got []string
+			for _, d := range diffJSON(decode(t, tt.expected), decode(t, tt.actual), opts) {
+				got = append(got, string(d.Kind)+" "+d.Path)
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("diffJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffJSONDifferences(t *testing.T) {
+	expected := decode(t, `{"name":"Ann","a/b":{"~k":1},"tags":["x"],"gone":true,"n":null}`)
+	actual := decode(t, `{"name":"Bob","a/b":{"~k":"1"},"tags":["x","y"],"n":0,"extra":[1]}`)
+
+	got := diffJSON(expected, actual, CompareOptions{})
+	want := []Difference{
+		{Path: "/a~1b/~0k", Kind: DiffChanged, Old: 1.0, New: "1"},
+		{Path: "/gone", Kind: DiffRemoved, Old: true},
+		{Path: "/n", Kind: DiffChanged, Old: nil, New: 0.0},
+		{Path: "/name", Kind: DiffChanged, Old: "Ann", New: "Bob"},
+		{Path: "/tags/1", Kind: DiffAdded, New: "y"},
+		{Path: "/extra", Kind: DiffAdded, New: []interface{}{1.0}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("diffJSON() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if g, w := compactJSON(got[i]), compactJSON(want[i]); g != w {
+			t.Errorf("difference %d = %s, want %s", i, g, w)
+		}
+	}
+
+	if root := diffJSON(decode(t, `[1]`), decode(t, `{"a":1}`), CompareOptions{}); len(root) != 1 || root[0].Path != "" || root[0].Kind != DiffChanged {
+		t.Errorf("diffJSON(array, object) = %+v, want one change of the whole document", root)
+	}
+}
+
+func TestSummarizeAndFormatDiff(t *testing.T) {
+	diffs := []Difference{
+		{Path: "/name", Kind: DiffChanged, Old: "Ann", New: "Bob"},
+		{Path: "/id", Kind: DiffRemoved, Old: 1.0},
+		{Path: "", Kind: DiffChanged, Old: []interface{}{}, New: map[string]interface{}{}},
+		{Path: "/extra", Kind: DiffAdded, New: strings.Repeat("x", 100)},
+	}
+
+	if got, want := summarizeDiff(diffs), "4 differences: 2 changed, 1 removed, 1 added"; got != want {
+		t.Errorf("summarizeDiff() = %q, want %q", got, want)
+	}
+	if got, want := summarizeDiff(diffs[1:2]), "1 difference: 1 removed"; got != want {
+		t.Errorf("summarizeDiff() = %q, want %q", got, want)
+	}
+
+	want := strings.Join([]string{
+		`~ /name: "Ann" -> "Bob"`,
+		`- /id: 1`,
+		`~ /: [] -> {}`,
+		`+ /extra: "` + strings.Repeat("x", 56) + `...`,
+	}, "\n")
+	if got := formatDiff(diffs, 10); got != want {
+		t.Errorf("formatDiff() =\n%s\nwant\n%s", got, want)
+	}
+	if got, want := formatDiff(diffs, 2), "~ /name: \"Ann\" -> \"Bob\"\n- /id: 1\n... and 2 more"; got != want {
+		t.Errorf("formatDiff(limit 2) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCompactJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"short", map[string]interface{}{"a": 1}, `{"a":1}`},
+		{"exactly the limit", strings.Repeat("x", 58), `"` + strings.Repeat("x", 58) + `"`},
+		{"ASCII cut", strings.Repeat("x", 70), `"` + strings.Repeat("x", 56) + `...`},
+		{"multi-byte characters cut whole", strings.Repeat("ñ", 70), `"` + strings.Repeat("ñ", 56) + `...`},
+		{"multi-byte characters under the limit in runes", strings.Repeat("日", 58), `"` + strings.Repeat("日", 58) + `"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compactJSON(tt.value)
+			if got != tt.want {
+				t.Errorf("compactJSON() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("compactJSON() = %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestResultSummary(t *testing.T) {
+	tests := []struct {
+		result Result
+		want   string
+	}{
+		{Result{Status: StatusPassed, Message: "Test passed successfully"}, "Test successful"},
+		{Result{Status: StatusFailed, Message: "Response does not match ...", Diff: []Difference{{Path: "/a", Kind: DiffAdded}}}, "Response does not match (1 difference: 1 added)"},
+		{Result{Status: StatusFailed, Message: "Response does not match schema (1 violation(s)):\n! /id: expected integer"}, "Response does not match schema (1 violation(s))"},
+		{Result{Status: StatusBlocked, Message: "Blocked by A (failed)"}, "Blocked by A (failed)"},
+	}
+	for _, tt := range tests {
+		if got := tt.result.Summary(); got != tt.want {
+			t.Errorf("Summary() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -6,26 +6,63 @@ import (
 	"encoding/json"
 	"fmt"
 	"go-api-testing/internal/api"
+	"go-api-testing/internal/openapi"
+	"go-api-testing/internal/schema"
 	"go-api-testing/models"
-	"reflect"
+	"time"
 )
 
+// maxDiffLines is the number of differences listed in the message of a failed test.
+// The complete list is always available in Result.Diff.
+const maxDiffLines = 10
+
 // RunTest executes a test based on a specified test case.
 // The function performs an HTTP request using the test case data,
 // then compares the obtained response with the expected one.
 // If both the status code and response body match the expectations, the test passes.
+// The response body comparison honours the test's CompareOptions (ignored paths,
+// unordered arrays, numeric tolerances and extra fields), and the body must also
+// satisfy the test's ResponseSchema when one is given.
+// When an OpenAPI contract is given, the exchange is also validated against it and
+// the violations are reported as contract failures, on top of the outcome of the
+// test's own expectations: a test that meets them fails if it breaks the contract.
 //
 // Parameters:
 //   - test (models.TestCase): Test case containing method, URL, headers, body, auth, and expected responses.
+//   - contract (*openapi.Document): OpenAPI document the exchange must honour, or nil.
 //
 // Returns:
-//   - bool: true if the test was successful, false otherwise.
-//   - string: Detailed message about the test result.
-func RunTest(test models.TestCase) (bool, string) {
+//   - Result: The outcome of the test, with a detailed message, the HTTP exchange and,
+//     when the response body does not match, the path-level differences found and
+//     the contract violations.
+func RunTest(test models.TestCase, contract *openapi.Document) (result Result) {
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	result = verify(test)
+	if contract == nil || result.Exchange == nil || result.Exchange.Response.StatusCode == 0 {
+		return result
+	}
+	if result.Contract = contract.Check(*result.Exchange); len(result.Contract) == 0 {
+		return result
+	}
+	message := fmt.Sprintf("Contract violation (%d):\n%s", len(result.Contract), formatContract(result.Contract, maxDiffLines))
+	if result.Passed() {
+		result.Status, result.Message = StatusFailed, message
+	} else {
+		result.Message += "\n" + message
+	}
+	return result
+}
+
+// verify executes a test case and checks its expectations.
+func verify(test models.TestCase) (result Result) {
+	result = Result{TestCase: test, Status: StatusFailed}
+
 	fullURL := test.URL + test.Endpoint
 
-	// Perform HTTP request
-	statusCode, response, err := api.RealizarSolicitud(
+	// Perform HTTP request, keeping the exchange for troubleshooting
+	exchange, err := api.Send(
 		test.Method,
 		fullURL,
 		test.Body,
@@ -34,51 +71,84 @@ func RunTest(test models.TestCase) (bool, string) {
 		test.User,
 		test.Password,
 	)
+	result.Exchange = &exchange
+	statusCode, response := exchange.Response.StatusCode, exchange.Response.Body
 
 	if err != nil {
 		// Request or authentication error
-		msg := fmt.Sprintf("Error in request: %v", err)
-		return false, msg
+		result.Message = fmt.Sprintf("Error in request: %v", err)
+		return result
 	}
 
 	// Check status code
 	if statusCode != test.ExpectedStatusCode {
-		msg := fmt.Sprintf(
+		result.Message = fmt.Sprintf(
 			"Incorrect status code: expected %d, got %d",
 			test.ExpectedStatusCode,
 			statusCode,
 		)
-		return false, msg
+		return result
 	}
 
 	// Check expected response only if it's not empty
 	if test.ExpectedResponse != "" {
-		var expected, actual map[string]interface{}
+		var expected, actual interface{}
+
+		// Parse the comparison options of the test case
+		opts, err := ParseCompareOptions(test.CompareOptions)
+		if err != nil {
+			result.Message = fmt.Sprintf("Error parsing compare options: %v", err)
+			return result
+		}
 
 		// Deserialize expected response
 		if err := json.Unmarshal([]byte(test.ExpectedResponse), &expected); err != nil {
-			msg := fmt.Sprintf("Error deserializing expected response: %v", err)
-			return false, msg
+			result.Message = fmt.Sprintf("Error deserializing expected response: %v", err)
+			return result
 		}
 
 		// Deserialize obtained response
 		if err := json.Unmarshal([]byte(response), &actual); err != nil {
-			msg := fmt.Sprintf("Error deserializing obtained response: %v", err)
-			return false, msg
+			result.Message = fmt.Sprintf("Error deserializing obtained response: %v", err)
+			return result
+		}
+
+		// Compare JSON structures path by path
+		if diffs := diffJSON(expected, actual, opts); len(diffs) > 0 {
+			result.Diff = diffs
+			result.Message = fmt.Sprintf(
+				"Response does not match (%s):\n%s",
+				summarizeDiff(diffs),
+				formatDiff(diffs, maxDiffLines),
+			)
+			return result
 		}
+	}
 
-		// Compare JSON structures
-		if !reflect.DeepEqual(expected, actual) {
-			expectedJSON, _ := json.MarshalIndent(expected, "", "  ")
-			actualJSON, _ := json.MarshalIndent(actual, "", "  ")
-			msg := fmt.Sprintf(
-				"Response does not match:\nExpected: %s\nObtained: %s",
-				expectedJSON, actualJSON,
+	// Check the response body against the JSON Schema only if one is given
+	if test.ResponseSchema != "" {
+		responseSchema, err := schema.Load(test.ResponseSchema)
+		if err != nil {
+			result.Message = fmt.Sprintf("Error loading response schema: %v", err)
+			return result
+		}
+		var actual interface{}
+		if err := json.Unmarshal([]byte(response), &actual); err != nil {
+			result.Message = fmt.Sprintf("Error deserializing obtained response: %v", err)
+			return result
+		}
+		if violations := schema.Validate(responseSchema, actual); len(violations) > 0 {
+			result.Message = fmt.Sprintf(
+				"Response does not match schema (%d violation(s)):\n%s",
+				len(violations),
+				formatViolations(violations, maxDiffLines),
 			)
-			return false, msg
+			return result
 		}
 	}
 
 	// Everything is correct
-	return true, "Test passed successfully"
+	result.Status = StatusPassed
+	result.Message = "Test passed successfully"
+	return result
 }
@@ -0,0 +1,167 @@
+package test
+
+import (
+	"fmt"
+	"go-api-testing/internal/openapi"
+	"go-api-testing/models"
+	"strings"
+)
+
+// Plan is the dependency graph of a test suite. Each test case is a node and
+// each entry of its DependsOn column is an edge from the dependency to it.
+// A Plan is guaranteed to be acyclic, so its tests can always be scheduled.
+type Plan struct {
+	Tests      []models.TestCase // Test cases in the order they were loaded.
+	Contract   *openapi.Document // OpenAPI document every exchange is validated against, nil for none.
+	deps       [][]int           // deps[i] holds the indexes of the tests that test i depends on.
+	dependents [][]int           // dependents[i] holds the indexes of the tests that depend on test i.
+}
+
+// BuildPlan builds the dependency graph of the given test cases and validates it.
+// It fails if a test depends on itself, on an unknown or ambiguous TestId, or
+// if the dependencies form a cycle.
+//
+// Parameters:
+//   - testCases ([]models.TestCase): The test cases as loaded from the CSV file.
+//
+// Returns:
+//   - *Plan: The validated dependency graph.
+//   - error: A description of the first invalid dependency or cycle found.
+func BuildPlan(testCases []models.TestCase) (*Plan, error) {
+	index := make(map[string]int, len(testCases))
+	duplicated := make(map[string]bool)
+	for i, tc := range testCases {
+		if _, ok := index[tc.TestId]; ok {
+			duplicated[tc.TestId] = true
+			continue
+		}
+		index[tc.TestId] = i
+	}
+
+	plan := &Plan{
+		Tests:      testCases,
+		deps:       make([][]int, len(testCases)),
+		dependents: make([][]int, len(testCases)),
+	}
+	for i, tc := range testCases {
+		seen := make(map[int]bool)
+		for _, id := range tc.Dependencies() {
+			j, ok := index[id]
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("test %s depends on unknown test %s", tc.TestId, id)
+			case duplicated[id]:
+				return nil, fmt.Errorf("test %s depends on %s, which is defined more than once", tc.TestId, id)
+			case j == i:
+				return nil, fmt.Errorf("test %s depends on itself", tc.TestId)
+			case seen[j]:
+				continue
+			}
+			seen[j] = true
+			plan.deps[i] = append(plan.deps[i], j)
+			plan.dependents[j] = append(plan.dependents[j], i)
+		}
+	}
+
+	if cycle := plan.findCycle(); cycle != nil {
+		return nil, fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+	return plan, nil
+}
+
+// findCycle looks for a dependency cycle using a depth-first search.
+// It returns the TestIds forming the cycle (the first one repeated at the end),
+// or nil if the graph is acyclic.
+func (p *Plan) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(p.Tests))
+	var stack []int
+
+	var visit func(i int) []string
+	visit = func(i int) []string {
+		state[i] = visiting
+		stack = append(stack, i)
+		for _, j := range p.deps[i] {
+			switch state[j] {
+			case visiting:
+				var cycle []string
+				for k := len(stack) - 1; k >= 0; k-- {
+					cycle = append([]string{p.Tests[stack[k]].TestId}, cycle...)
+					if stack[k] == j {
+						break
+					}
+				}
+				return append(cycle, p.Tests[j].TestId)
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = done
+		return nil
+	}
+
+	for i := range p.Tests {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// SelectByTags keeps the test cases that have at least one of the given tags, together
+// with every test they depend on, directly or not, so that the selection can still be
+// planned. Tags are compared ignoring case.
+//
+// Parameters:
+//   - testCases ([]models.TestCase): The test cases as loaded from the CSV file.
+//   - tags ([]string): The tags to select.
+//
+// Returns:
+//   - []models.TestCase: The selected test cases, in their original order.
+func SelectByTags(testCases []models.TestCase, tags []string) []models.TestCase {
+	wanted := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		wanted[strings.ToLower(strings.TrimSpace(tag))] = true
+	}
+	byID := make(map[string]models.TestCase, len(testCases))
+	for _, tc := range testCases {
+		byID[tc.TestId] = tc
+	}
+
+	selected := make(map[string]bool)
+	var pending []string
+	for _, tc := range testCases {
+		for _, tag := range tc.TagList() {
+			if wanted[strings.ToLower(tag)] {
+				pending = append(pending, tc.TestId)
+				break
+			}
+		}
+	}
+	for len(pending) > 0 {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if selected[id] {
+			continue
+		}
+		selected[id] = true
+		pending = append(pending, byID[id].Dependencies()...)
+	}
+
+	var result []models.TestCase
+	for _, tc := range testCases {
+		if selected[tc.TestId] {
+			result = append(result, tc)
+		}
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"go-api-testing/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newCase builds a test case that calls endpoint on url and expects a 200 response.
+func newCase(id, dependsOn, url, endpoint string) models.TestCase {
+	return models.TestCase{TestId: id, TestCase: id, Run: "Y", Method: http.MethodGet, URL: url, Endpoint: endpoint, DependsOn: dependsOn, ExpectedStatusCode: http.StatusOK}
+}
+
+func TestBuildPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		cases   []models.TestCase
+		wantErr string // Empty when the plan is valid.
+	}{
+		{"no dependencies", []models.TestCase{newCase("A", "", "", ""), newCase("B", "", "", "")}, ""},
+		{"chain and diamond", []models.TestCase{newCase("A", "", "", ""), newCase("B", "A", "", ""), newCase("C", "A", "", ""), newCase("D", "B; C, A", "", "")}, ""},
+		{"repeated dependency", []models.TestCase{newCase("A", "", "", ""), newCase("B", "A A", "", "")}, ""},
+		{"unknown dependency", []models.TestCase{newCase("A", "Z", "", "")}, "test A depends on unknown test Z"},
+		{"self dependency", []models.TestCase{newCase("A", "A", "", "")}, "test A depends on itself"},
+		{"ambiguous dependency", []models.TestCase{newCase("A", "", "", ""), newCase("A", "", "", ""), newCase("B", "A", "", "")}, "defined more than once"},
+		{"two-node cycle", []models.TestCase{newCase("A", "B", "", ""), newCase("B", "A", "", "")}, "dependency cycle detected: A -> B -> A"},
+		{"cycle behind a valid node", []models.TestCase{newCase("A", "", "", ""), newCase("B", "A D", "", ""), newCase("C", "B", "", ""), newCase("D", "C", "", "")}, "dependency cycle detected: B -> D -> C -> B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := BuildPlan(tt.cases)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("BuildPlan() error = %v, want none", err)
+				}
+				if len(plan.Tests) != len(tt.cases) {
+					t.Errorf("plan has %d tests, want %d", len(plan.Tests), len(tt.cases))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("BuildPlan() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlanRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	disabled := newCase("D", "", server.URL, "/ok")
+	disabled.Run = "N"
+	cases := []models.TestCase{
+		newCase("C", "B", server.URL, "/ok"), // Listed before its dependencies
+		newCase("B", "A", server.URL, "/ok"),
+		newCase("A", "", server.URL, "/fail"),
+		disabled,
+		newCase("E", "D", server.URL, "/ok"),
+		newCase("F", "", server.URL, "/ok"),
+		newCase("G", "F", server.URL, "/ok"),
+	}
+	want := map[string]Status{
+		"A": StatusFailed,
+		"B": StatusBlocked,
+		"C": StatusBlocked,
+		"D": StatusSkipped,
+		"E": StatusBlocked,
+		"F": StatusPassed,
+		"G": StatusPassed,
+	}
+
+	for _, workers := range []int{0, 1, 3} {
+		plan, err := BuildPlan(cases)
+		if err != nil {
+			t.Fatalf("BuildPlan() error = %v", err)
+		}
+		var order []string
+		results := plan.Run(workers, func(r Result) { order = append(order, r.TestCase.TestId) })
+
+		if len(results) != len(cases) || len(order) != len(cases) {
+			t.Fatalf("workers=%d: got %d results and %d callbacks, want %d", workers, len(results), len(order), len(cases))
+		}
+		finished := make(map[string]int, len(order))
+		for i, id := range order {
+			finished[id] = i
+		}
+		for i, r := range results {
+			id := r.TestCase.TestId
+			if id != cases[i].TestId {
+				t.Errorf("workers=%d: results[%d] is %s, want %s (suite order)", workers, i, id, cases[i].TestId)
+			}
+			if r.Status != want[id] {
+				t.Errorf("workers=%d: %s status = %s, want %s (%s)", workers, id, r.Status, want[id], r.Message)
+			}
+			for _, dep := range cases[i].Dependencies() {
+				if finished[dep] > finished[id] {
+					t.Errorf("workers=%d: %s finished before its dependency %s", workers, id, dep)
+				}
+			}
+		}
+		if msg := results[0].Message; msg != "Blocked by B (blocked)" {
+			t.Errorf("workers=%d: C message = %q, want %q", workers, msg, "Blocked by B (blocked)")
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"fmt"
+	"go-api-testing/internal/api"
+	"go-api-testing/internal/openapi"
+	"go-api-testing/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status is the outcome of a test case within a run.
+type Status string
+
+const (
+	StatusPassed  Status = "passed"  // The test ran and met every expectation.
+	StatusFailed  Status = "failed"  // The test ran and did not meet its expectations.
+	StatusSkipped Status = "skipped" // The test was not executed because Run is not "Y".
+	StatusBlocked Status = "blocked" // The test was not executed because a dependency did not pass.
+)
+
+// Result holds the outcome of a single test case execution.
+type Result struct {
+	TestCase models.TestCase     // The executed test case.
+	Status   Status              // Outcome of the test.
+	Message  string              // Detailed message about the result.
+	Duration time.Duration       // Time spent executing the test (zero if it was not executed).
+	Diff     []Difference        // Differences between the expected and the obtained response, if any.
+	Exchange *api.Exchange       // Request sent and response received (nil if the test was not executed).
+	Contract []openapi.Violation // Ways in which the exchange breaks the OpenAPI contract, if one was given.
+}
+
+// Passed reports whether the test ran and passed.
+func (r Result) Passed() bool {
+	return r.Status == StatusPassed
+}
+
+// Summary returns a short, single-line description of the result, suitable for
+// the console: the difference counts for a response mismatch, or the first line
+// of the message otherwise. Contract violations are counted at the end.
+func (r Result) Summary() string {
+	summary := r.summary()
+	if len(r.Contract) > 0 && !strings.HasPrefix(summary, "Contract violation") {
+		summary += fmt.Sprintf(" + %d contract violation(s)", len(r.Contract))
+	}
+	return summary
+}
+
+// summary returns the summary of the result, leaving out the contract violations
+// of tests that failed their own expectations.
+func (r Result) summary() string {
+	switch {
+	case r.Status == StatusPassed:
+		return "Test successful"
+	case len(r.Diff) > 0:
+		return "Response does not match (" + summarizeDiff(r.Diff) + ")"
+	default:
+		line, _, _ := strings.Cut(r.Message, "\n")
+		return strings.TrimSuffix(line, ":")
+	}
+}
+
+// Run executes the test cases of the plan respecting their dependencies.
+// Tests whose dependencies have all passed are executed by up to workers
+// goroutines at the same time; among the tests that are ready, the one that
+// appears first in the suite is always started first, so a single worker runs
+// the suite in file order whenever the dependencies allow it.
+// Tests with Run other than "Y" are reported as skipped, and tests depending on a
+// test that did not pass are reported as blocked without being executed.
+//
+// Parameters:
+//   - workers (int): Maximum number of tests executed concurrently (values below 1 mean 1).
+//   - onResult (func(Result)): Optional callback invoked, from the calling goroutine,
+//     as soon as each test finishes.
+//
+// Returns:
+//   - []Result: The results of every test case, in the same order as Plan.Tests.
+func (p *Plan) Run(workers int, onResult func(Result)) []Result {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type finished struct {
+		index  int
+		result Result
+	}
+	jobs := make(chan int)
+	done := make(chan finished, workers)
+	defer close(jobs)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				done <- finished{i, RunTest(p.Tests[i], p.Contract)}
+			}
+		}()
+	}
+
+	results := make([]Result, len(p.Tests))
+	pending := make([]int, len(p.Tests))
+	var ready []int
+	for i := range p.Tests {
+		pending[i] = len(p.deps[i])
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	complete := func(i int, r Result) {
+		results[i] = r
+		if onResult != nil {
+			onResult(r)
+		}
+		for _, j := range p.dependents[i] {
+			pending[j]--
+			if pending[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+		sort.Ints(ready)
+	}
+
+	inFlight := 0
+	for {
+		for inFlight < workers && len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+			if r, ok := p.resolveWithoutRunning(i, results); ok {
+				complete(i, r)
+				continue
+			}
+			jobs <- i
+			inFlight++
+		}
+		if inFlight == 0 {
+			break
+		}
+		f := <-done
+		inFlight--
+		complete(f.index, f.result)
+	}
+	return results
+}
+
+// resolveWithoutRunning decides whether test i can be settled without executing it,
+// either because it is disabled or because one of its dependencies did not pass.
+func (p *Plan) resolveWithoutRunning(i int, results []Result) (Result, bool) {
+	tc := p.Tests[i]
+	if tc.Run != "Y" {
+		return Result{TestCase: tc, Status: StatusSkipped, Message: "Test skipped"}, true
+	}
+
+	var blockers []string
+	for _, j := range p.deps[i] {
+		if dep := results[j]; !dep.Passed() {
+			blockers = append(blockers, fmt.Sprintf("%s (%s)", dep.TestCase.TestId, dep.Status))
+		}
+	}
+	if len(blockers) > 0 {
+		msg := "Blocked by " + strings.Join(blockers, ", ")
+		return Result{TestCase: tc, Status: StatusBlocked, Message: msg}, true
+	}
+	return Result{}, false
+}
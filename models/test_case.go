@@ -1,6 +1,11 @@
 // Package models defines the structures used in testing, such as test cases in CSV format.
 package models
 
+import (
+	"strings"
+	"unicode"
+)
+
 // TestCase represents a test case containing the necessary information to execute an API test.
 // The structure maps directly to a CSV file where each column corresponds to a test field.
 //
@@ -21,6 +26,13 @@ package models
 //   - Body: The body of the request, sent in cases like "POST" or "PUT".
 //   - ExpectedStatusCode: The expected HTTP status code in the response.
 //   - ExpectedResponse: The expected API response in JSON format, to compare with the actual response.
+//   - DependsOn: TestIds that must pass before this test runs, separated by ";" (optional).
+//   - Data: Data table that expands the test into one case per row: an inline JSON list
+//     or the path to a CSV/JSON file (optional).
+//   - CompareOptions: JSON object tuning the response comparison (optional).
+//   - Tags: Labels used to select tests, separated by ";" (optional).
+//   - ResponseSchema: JSON Schema the response body must satisfy: an inline JSON object
+//     or the path to a .json file (optional).
 type TestCase struct {
 	TestId             string `json:"TestId"`             // Test case identifier.
 	TestCase           string `json:"TestCase"`           // Name or description of the test case.
@@ -35,4 +47,35 @@ type TestCase struct {
 	Body               string `json:"Body"`               // Request body (for POST, PUT).
 	ExpectedStatusCode int    `json:"ExpectedStatusCode"` // Expected HTTP status code in the response.
 	ExpectedResponse   string `json:"ExpectedResponse"`   // Expected response in JSON format.
+	DependsOn          string `json:"DependsOn"`          // TestIds this test depends on (e.g., "TC-001;TC-002").
+	Data               string `json:"Data"`               // Data table for parameterized tests (inline JSON or file path).
+	CompareOptions     string `json:"CompareOptions"`     // Response comparison options in JSON format.
+	Tags               string `json:"Tags"`               // Labels of the test case (e.g., "users;smoke").
+	ResponseSchema     string `json:"ResponseSchema"`     // JSON Schema of the response body (inline JSON or file path).
+}
+
+// Dependencies returns the TestIds listed in the DependsOn field.
+// Identifiers may be separated by semicolons, commas or whitespace; empty entries are ignored.
+//
+// Returns:
+//   - []string: The TestIds this test case depends on, in the order they were declared.
+func (tc TestCase) Dependencies() []string {
+	return strings.FieldsFunc(tc.DependsOn, func(r rune) bool {
+		return r == ';' || r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// TagList returns the labels listed in the Tags field.
+// Tags may be separated by semicolons or commas; surrounding spaces and empty entries are ignored.
+//
+// Returns:
+//   - []string: The tags of the test case, in the order they were declared.
+func (tc TestCase) TagList() []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(tc.Tags, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
 }
@@ -0,0 +1,64 @@
+@echo off
+REM --------------------------------------------------------
+REM fetch-report-assets.bat: Download the libraries embedded in offline HTML reports
+REM --------------------------------------------------------
+
+REM Description:
+REM   This script downloads the CSS and JavaScript libraries used by the HTML report
+REM   into internal\report\assets\vendor, and checks each file against its pinned
+REM   SHA-256. The URLs and SHA-256 must match the ones listed in
+REM   internal\report\assets.go. A file that does not match (or has no pinned SHA-256
+REM   yet) is not installed. Once the binary is rebuilt, REPORT_ASSETS=inline produces
+REM   reports that can be opened without Internet access.
+REM
+REM Usage:
+REM   Run the script from the root of the repository:
+REM   scripts\fetch-report-assets.bat
+REM
+REM Requirements:
+REM   - curl and certutil (included in Windows 10 and later).
+REM   - Internet access (only while running this script).
+
+REM --------------------------------------------------------
+
+setlocal EnableDelayedExpansion
+set VENDOR_DIR=internal\report\assets\vendor
+if not exist "%VENDOR_DIR%" mkdir "%VENDOR_DIR%"
+set FAILED=0
+
+call :fetch https://cdn.jsdelivr.net/npm/bootstrap@5.3.1/dist/css/bootstrap.min.css bootstrap.min.css ""
+call :fetch https://cdn.datatables.net/1.13.6/css/dataTables.bootstrap5.min.css dataTables.bootstrap5.min.css ""
+call :fetch https://cdn.jsdelivr.net/npm/chart.js@4.4.0/dist/chart.umd.js chart.umd.js ""
+call :fetch https://code.jquery.com/jquery-3.7.1.min.js jquery-3.7.1.min.js ""
+call :fetch https://cdn.datatables.net/1.13.6/js/jquery.dataTables.min.js jquery.dataTables.min.js ""
+call :fetch https://cdn.datatables.net/1.13.6/js/dataTables.bootstrap5.min.js dataTables.bootstrap5.min.js ""
+call :fetch https://cdn.jsdelivr.net/npm/xlsx@0.18.5/dist/xlsx.full.min.js xlsx.full.min.js ""
+
+if "%FAILED%"=="1" (
+    echo Some libraries could not be verified. Check their SHA-256 against the published checksums and pin them in internal\report\assets.go and in this script.
+    exit /b 1
+)
+echo Report assets saved in "%VENDOR_DIR%". Rebuild the binary and set REPORT_ASSETS=inline to embed them.
+exit /b 0
+
+:fetch
+echo Downloading %1...
+set DOWNLOAD=%VENDOR_DIR%\%2.download
+curl -fsSL %1 -o "%DOWNLOAD%" || exit /b 1
+set ACTUAL=
+for /f "skip=1 delims=" %%h in ('certutil -hashfile "%DOWNLOAD%" SHA256') do if not defined ACTUAL set ACTUAL=%%h
+set ACTUAL=!ACTUAL: =!
+if "%~3"=="" (
+    echo Error: %2 has no pinned SHA-256; the downloaded file has !ACTUAL!.
+    del "%DOWNLOAD%"
+    set FAILED=1
+    exit /b 0
+)
+if /i not "!ACTUAL!"=="%~3" (
+    echo Error: %2 has SHA-256 !ACTUAL!, expected %~3. It is not installed.
+    del "%DOWNLOAD%"
+    set FAILED=1
+    exit /b 0
+)
+move /y "%DOWNLOAD%" "%VENDOR_DIR%\%2" > nul
+exit /b 0
@@ -0,0 +1,84 @@
+#!/bin/bash
+
+# --------------------------------------------------------
+# fetch-report-assets.sh: Download the libraries embedded in offline HTML reports
+# --------------------------------------------------------
+
+# Description:
+#   This script downloads the CSS and JavaScript libraries used by the HTML report
+#   into internal/report/assets/vendor, from the CDN URLs listed in
+#   internal/report/assets.go, and checks each file against the SHA-256 pinned there.
+#   A file that does not match (or has no pinned SHA-256 yet) is not installed.
+#   Once the binary is rebuilt, REPORT_ASSETS=inline produces reports that can be
+#   opened without Internet access.
+#
+# Usage:
+#   Run the script from the root of the repository:
+#   ./scripts/fetch-report-assets.sh
+#
+# Pinning a library (new or upgraded):
+#   The script prints the SHA-256 of every file it could not verify. Check it against
+#   the checksum published by the library (e.g. the SRI hash of its CDN snippet),
+#   set it as the third field of the library in assets.go and run the script again.
+#
+# Requirements:
+#   - curl and sha256sum (or shasum on Mac) must be installed on the system.
+#   - Internet access (only while running this script).
+
+# --------------------------------------------------------
+
+set -e
+
+ASSETS_FILE="internal/report/assets.go"
+VENDOR_DIR="internal/report/assets/vendor"
+
+if ! command -v curl &> /dev/null; then
+    echo "Error: curl is not installed. Please install curl."
+    exit 1
+fi
+if command -v sha256sum &> /dev/null; then
+    SHA256="sha256sum"
+elif command -v shasum &> /dev/null; then
+    SHA256="shasum -a 256"
+else
+    echo "Error: sha256sum (or shasum) is not installed."
+    exit 1
+fi
+if [ ! -f "$ASSETS_FILE" ]; then
+    echo "Error: '$ASSETS_FILE' not found. Run this script from the root of the repository."
+    exit 1
+fi
+
+# Each library is declared in assets.go as {"<url>", "<file>", "<sha256>"}
+LIBRARIES=$(grep -oE '\{"https://[^"]+", "[^"]+", "[0-9a-f]*"\}' "$ASSETS_FILE" |
+    sed -E 's/\{"([^"]+)", "([^"]+)", "([0-9a-f]*)"\}/\1 \2 \3/')
+if [ -z "$LIBRARIES" ]; then
+    echo "Error: no libraries found in '$ASSETS_FILE'."
+    exit 1
+fi
+
+mkdir -p "$VENDOR_DIR"
+FAILED=0
+while read -r URL FILE PINNED; do
+    echo "Downloading $URL..."
+    DOWNLOAD="$VENDOR_DIR/$FILE.download"
+    curl -fsSL "$URL" -o "$DOWNLOAD"
+    ACTUAL=$($SHA256 "$DOWNLOAD" | cut -d' ' -f1)
+    if [ -z "$PINNED" ]; then
+        echo "Error: $FILE has no pinned SHA-256 in $ASSETS_FILE; the downloaded file has $ACTUAL."
+        rm -f "$DOWNLOAD"
+        FAILED=1
+    elif [ "$ACTUAL" != "$PINNED" ]; then
+        echo "Error: $FILE has SHA-256 $ACTUAL, expected $PINNED. It is not installed."
+        rm -f "$DOWNLOAD"
+        FAILED=1
+    else
+        mv "$DOWNLOAD" "$VENDOR_DIR/$FILE"
+    fi
+done <<< "$LIBRARIES"
+
+if [ "$FAILED" -ne 0 ]; then
+    echo "Some libraries could not be verified. Check their SHA-256 against the published checksums and pin them in $ASSETS_FILE."
+    exit 1
+fi
+echo "Report assets saved in '$VENDOR_DIR'. Rebuild the binary and set REPORT_ASSETS=inline to embed them."